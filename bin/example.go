@@ -12,6 +12,9 @@ func main() {
 	dumpCpuInfos()
 	dumpNetworkInterfaces()
 	dumpMemInfos()
+	dumpRoutes()
+	dumpNeighbors()
+	dumpListeningPorts()
 }
 
 func dumpSimple() {
@@ -143,3 +146,50 @@ func dumpMemInfos() {
 	fmt.Printf(format, "SwapTotal", mi.SwapTotal, mi.UnitUsed)
 	fmt.Printf(format, "SwapFree", mi.SwapFree, mi.UnitUsed)
 }
+
+func dumpRoutes() {
+	routes, err := libsysinfo.Routes()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("\nRoutes\n---------\n")
+	format := "- %-40s via %-25s dev %-10s metric %d\n"
+
+	for _, r := range routes {
+		dst := fmt.Sprintf("%s/%d", r.Destination, r.PrefixLen())
+		fmt.Printf(format, dst, r.Gateway, r.Interface, r.Metric)
+	}
+}
+
+func dumpNeighbors() {
+	neighbors, err := libsysinfo.Neighbors()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("\nNeighbors\n---------\n")
+	format := "- %-40s lladdr %-17s dev %-10s %s\n"
+
+	for _, n := range neighbors {
+		fmt.Printf(format, n.IP, n.HWAddr, n.Interface, n.State)
+	}
+}
+
+func dumpListeningPorts() {
+	ports, err := libsysinfo.ListeningPorts()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("\nListening ports\n---------------\n")
+	format := "- %-5s %-40s %-6d"
+
+	for _, p := range ports {
+		fmt.Printf(format, p.Protocol, p.Addr, p.Port)
+		for _, o := range p.Owners {
+			fmt.Printf(" %s(%d)", o.Command, o.PID)
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,225 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	sysBlockPath = "/sys/block"
+
+	// sysfs always expresses sizes in 512 bytes sectors, regardless of the
+	// actual sector size of the device
+	sysfsSectorSize = 512
+)
+
+const (
+	TransportNVMe    = "nvme"
+	TransportSATA    = "sata"
+	TransportSCSI    = "scsi"
+	TransportUSB     = "usb"
+	TransportVirtio  = "virtio"
+	TransportMMC     = "mmc"
+	TransportLoop    = "loop"
+	TransportVirtual = "virtual"
+)
+
+// ----
+
+type BlockDevice struct {
+	Name  string
+	Major int
+	Minor int
+
+	// In bytes
+	Size               uint64
+	LogicalSectorSize  int
+	PhysicalSectorSize int
+
+	Rotational bool
+	Removable  bool
+	ReadOnly   bool
+
+	Model  string
+	Vendor string
+	Serial string
+	WWN    string
+
+	// The active I/O scheduler, "none" for multiqueue devices without one
+	Scheduler string
+
+	// The device queue depth when the driver exposes it (SCSI, SATA),
+	// otherwise the number of requests the block layer queues
+	QueueDepth int
+
+	// Devices stacked on top of this one, e.g. device-mapper or md arrays
+	Holders []string
+
+	// Devices this one is stacked on
+	Slaves []string
+
+	// One of the Transport* constants
+	Transport string
+
+	Partitions []BlockPartition
+}
+
+type BlockPartition struct {
+	Name   string
+	Major  int
+	Minor  int
+	Number int
+
+	// In bytes
+	Start uint64
+	Size  uint64
+
+	ReadOnly bool
+	Holders  []string
+}
+
+// ----
+
+func BlockDevices() ([]BlockDevice, error) {
+	var devices []BlockDevice
+
+	_, err := os.Stat(sysBlockPath)
+	if err != nil {
+		return devices, err
+	}
+
+	for _, name := range dirNames(sysBlockPath) {
+		devices = append(devices, readBlockDevice(sysBlockPath, name))
+	}
+
+	return devices, nil
+}
+
+// ----
+
+// Parses a "major:minor" device number as found in sysfs dev files
+func parseDevNumber(dev string) (int, int) {
+	parts := strings.Split(dev, ":")
+	if len(parts) != 2 {
+		return 0, 0
+	}
+
+	return atoi(parts[0]), atoi(parts[1])
+}
+
+func readBlockDevice(root string, name string) BlockDevice {
+	dir := filepath.Join(root, name)
+
+	bd := BlockDevice{
+		Name:               name,
+		Size:               sysfsUint64(filepath.Join(dir, "size")) * sysfsSectorSize,
+		LogicalSectorSize:  sysfsInt(filepath.Join(dir, "queue", "logical_block_size")),
+		PhysicalSectorSize: sysfsInt(filepath.Join(dir, "queue", "physical_block_size")),
+		Rotational:         sysfsBool(filepath.Join(dir, "queue", "rotational")),
+		Removable:          sysfsBool(filepath.Join(dir, "removable")),
+		ReadOnly:           sysfsBool(filepath.Join(dir, "ro")),
+		Model:              sysfsString(filepath.Join(dir, "device", "model")),
+		Vendor:             sysfsString(filepath.Join(dir, "device", "vendor")),
+		Scheduler:          bracketedValue(sysfsString(filepath.Join(dir, "queue", "scheduler"))),
+		Holders:            dirNames(filepath.Join(dir, "holders")),
+		Slaves:             dirNames(filepath.Join(dir, "slaves")),
+	}
+
+	bd.Major, bd.Minor = parseDevNumber(sysfsString(filepath.Join(dir, "dev")))
+
+	// depending on the driver these live either on the disk or on its device
+	bd.Serial = firstSysfsString(
+		filepath.Join(dir, "device", "serial"),
+		filepath.Join(dir, "serial"),
+	)
+	bd.WWN = firstSysfsString(
+		filepath.Join(dir, "device", "wwid"),
+		filepath.Join(dir, "wwid"),
+	)
+
+	bd.QueueDepth = sysfsInt(filepath.Join(dir, "device", "queue_depth"))
+	if bd.QueueDepth == 0 {
+		bd.QueueDepth = sysfsInt(filepath.Join(dir, "queue", "nr_requests"))
+	}
+
+	realPath, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		realPath = dir
+	}
+	bd.Transport = blockTransport(name, realPath)
+
+	for _, entry := range dirNames(dir) {
+		partDir := filepath.Join(dir, entry)
+
+		_, err := os.Stat(filepath.Join(partDir, "partition"))
+		if err != nil {
+			continue
+		}
+
+		bd.Partitions = append(bd.Partitions, readBlockPartition(partDir))
+	}
+
+	return bd
+}
+
+func readBlockPartition(dir string) BlockPartition {
+	p := BlockPartition{
+		Name:     filepath.Base(dir),
+		Number:   sysfsInt(filepath.Join(dir, "partition")),
+		Start:    sysfsUint64(filepath.Join(dir, "start")) * sysfsSectorSize,
+		Size:     sysfsUint64(filepath.Join(dir, "size")) * sysfsSectorSize,
+		ReadOnly: sysfsBool(filepath.Join(dir, "ro")),
+		Holders:  dirNames(filepath.Join(dir, "holders")),
+	}
+
+	p.Major, p.Minor = parseDevNumber(sysfsString(filepath.Join(dir, "dev")))
+
+	return p
+}
+
+func firstSysfsString(paths ...string) string {
+	for _, p := range paths {
+		s := sysfsString(p)
+		if s != "" {
+			return s
+		}
+	}
+
+	return ""
+}
+
+// Guesses how a disk is attached from its name and from its path in the
+// sysfs device tree, e.g. /sys/devices/pci0000:00/0000:00:1f.2/ata1/...
+func blockTransport(name string, devicePath string) string {
+	switch {
+	case strings.HasPrefix(name, "loop"):
+		return TransportLoop
+	case strings.HasPrefix(name, "nvme"):
+		return TransportNVMe
+	case strings.HasPrefix(name, "mmcblk"):
+		return TransportMMC
+	}
+
+	switch {
+	case strings.Contains(devicePath, "/usb"):
+		return TransportUSB
+	case strings.Contains(devicePath, "/ata"):
+		return TransportSATA
+	case strings.Contains(devicePath, "/virtio"):
+		return TransportVirtio
+	case strings.Contains(devicePath, "/devices/virtual/"):
+		return TransportVirtual
+	}
+
+	switch {
+	case strings.HasPrefix(name, "vd"):
+		return TransportVirtio
+	case strings.HasPrefix(name, "sd"), strings.HasPrefix(name, "sr"):
+		return TransportSCSI
+	}
+
+	return ""
+}
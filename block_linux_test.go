@@ -0,0 +1,136 @@
+package libsysinfo
+
+import (
+	. "launchpad.net/gocheck"
+	"os"
+	"path/filepath"
+)
+
+type BlockTestSuite struct{}
+
+var (
+	_ = Suite(&BlockTestSuite{})
+)
+
+func (s *BlockTestSuite) TestReadBlockDevice(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"sda/dev":                       "8:0\n",
+		"sda/size":                      "1953525168\n",
+		"sda/removable":                 "0\n",
+		"sda/ro":                        "0\n",
+		"sda/queue/logical_block_size":  "512\n",
+		"sda/queue/physical_block_size": "4096\n",
+		"sda/queue/rotational":          "1\n",
+		"sda/queue/scheduler":           "noop deadline [cfq]\n",
+		"sda/queue/nr_requests":         "128\n",
+		"sda/device/model":              "WDC WD10EZEX-08W\n",
+		"sda/device/vendor":             "ATA     \n",
+		"sda/device/wwid":               "t10.ATA     WDC WD10EZEX-08WN4A0                    WD-WCC6Y0000000\n",
+		"sda/device/queue_depth":        "31\n",
+		"sda/sda1/partition":            "1\n",
+		"sda/sda1/dev":                  "8:1\n",
+		"sda/sda1/start":                "2048\n",
+		"sda/sda1/size":                 "1048576\n",
+		"sda/sda1/ro":                   "0\n",
+		"sda/sda2/partition":            "2\n",
+		"sda/sda2/dev":                  "8:2\n",
+		"sda/sda2/start":                "1050624\n",
+		"sda/sda2/size":                 "1952474545\n",
+		"sda/sda2/ro":                   "0\n",
+		"sda/sda2/holders/dm-0":         "",
+	})
+
+	obtained := readBlockDevice(root, "sda")
+
+	expected := BlockDevice{
+		Name:               "sda",
+		Major:              8,
+		Minor:              0,
+		Size:               1000204886016,
+		LogicalSectorSize:  512,
+		PhysicalSectorSize: 4096,
+		Rotational:         true,
+		Model:              "WDC WD10EZEX-08W",
+		Vendor:             "ATA",
+		WWN:                "t10.ATA     WDC WD10EZEX-08WN4A0                    WD-WCC6Y0000000",
+		Scheduler:          "cfq",
+		QueueDepth:         31,
+		Transport:          TransportSCSI,
+		Partitions: []BlockPartition{
+			BlockPartition{
+				Name:   "sda1",
+				Major:  8,
+				Minor:  1,
+				Number: 1,
+				Start:  1048576,
+				Size:   536870912,
+			},
+			BlockPartition{
+				Name:    "sda2",
+				Major:   8,
+				Minor:   2,
+				Number:  2,
+				Start:   537919488,
+				Size:    999666967040,
+				Holders: []string{"dm-0"},
+			},
+		},
+	}
+
+	c.Assert(obtained, DeepEquals, expected)
+}
+
+func (s *BlockTestSuite) TestReadBlockDevice_Virtio(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"vda/dev":               "254:0\n",
+		"vda/size":              "536870912\n",
+		"vda/serial":            "overlayblk",
+		"vda/queue/scheduler":   "none [mq-deadline] kyber bfq\n",
+		"vda/queue/nr_requests": "256\n",
+	})
+
+	obtained := readBlockDevice(root, "vda")
+
+	c.Assert(obtained.Serial, Equals, "overlayblk")
+	c.Assert(obtained.Scheduler, Equals, "mq-deadline")
+	c.Assert(obtained.QueueDepth, Equals, 256)
+	c.Assert(obtained.Transport, Equals, TransportVirtio)
+	c.Assert(obtained.Partitions, IsNil)
+}
+
+func (s *BlockTestSuite) TestBlockTransport(c *C) {
+	expected := map[string][2]string{
+		TransportSATA:    {"sda", "/sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda"},
+		TransportUSB:     {"sdb", "/sys/devices/pci0000:00/0000:00:14.0/usb2/2-1/2-1:1.0/host6/target6:0:0/6:0:0:0/block/sdb"},
+		TransportSCSI:    {"sdc", "/sys/devices/pci0000:00/0000:00:10.0/host2/target2:0:0/2:0:0:0/block/sdc"},
+		TransportVirtio:  {"vda", "/sys/devices/pci0000:00/0000:00:02.0/virtio1/block/vda"},
+		TransportNVMe:    {"nvme0n1", "/sys/devices/pci0000:00/0000:00:1d.0/0000:3d:00.0/nvme/nvme0/nvme0n1"},
+		TransportMMC:     {"mmcblk0", "/sys/devices/platform/soc/fe340000.mmc/mmc_host/mmc0/mmc0:aaaa/block/mmcblk0"},
+		TransportLoop:    {"loop0", "/sys/devices/virtual/block/loop0"},
+		TransportVirtual: {"dm-0", "/sys/devices/virtual/block/dm-0"},
+	}
+
+	for transport, dev := range expected {
+		c.Assert(blockTransport(dev[0], dev[1]), Equals, transport)
+	}
+}
+
+// Creates files under root, creating intermediate directories as needed
+func writeFixtureFiles(c *C, root string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(root, name)
+
+		c.Assert(os.MkdirAll(filepath.Dir(path), 0755), IsNil)
+
+		f, err := os.Create(path)
+		c.Assert(err, IsNil)
+
+		_, err = f.WriteString(content)
+		c.Assert(err, IsNil)
+		c.Assert(f.Close(), IsNil)
+	}
+}
@@ -0,0 +1,178 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	CacheData        = "data"
+	CacheInstruction = "instruction"
+	CacheUnified     = "unified"
+)
+
+var (
+	ErrCPUCachesNotAvailable = &LibSysInfoErr{"CPU caches informations not available"}
+)
+
+// ----
+
+// A physical cache instance, shared by one or more CPUs
+type CPUCache struct {
+	Level int
+
+	// One of CacheData, CacheInstruction or CacheUnified
+	Type string
+
+	// In bytes
+	Size uint64
+
+	// Zero for fully associative caches or when not reported
+	Ways int
+
+	// In bytes
+	LineSize int
+
+	Sets int
+
+	// Ids of the CPUs sharing this cache
+	SharedCPUs []int
+}
+
+// ----
+
+// Returns the caches of the online CPUs, each cache shared by several CPUs
+// being reported once. Caches are sorted by level, type and first CPU.
+func CPUCaches() ([]CPUCache, error) {
+	_, err := os.Stat(sysCPUPath)
+	if err != nil {
+		return []CPUCache(nil), err
+	}
+
+	caches := readCPUCaches(sysCPUPath)
+	if len(caches) <= 0 {
+		return caches, ErrCPUCachesNotAvailable
+	}
+
+	return caches, nil
+}
+
+// ----
+
+func readCPUCaches(root string) []CPUCache {
+	var caches []CPUCache
+
+	seen := make(map[string]bool)
+
+	for _, id := range onlineCPUs(root) {
+		dir := filepath.Join(root, "cpu"+strconv.Itoa(id), "cache")
+
+		for _, name := range dirNames(dir) {
+			if !strings.HasPrefix(name, "index") {
+				continue
+			}
+
+			cc := readCPUCache(filepath.Join(dir, name))
+			if len(cc.SharedCPUs) <= 0 {
+				cc.SharedCPUs = []int{id}
+			}
+
+			// a shared cache is listed under every CPU sharing it
+			key := strconv.Itoa(cc.Level) + cc.Type + cpuListKey(cc.SharedCPUs)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			caches = append(caches, cc)
+		}
+	}
+
+	sort.Sort(cpuCachesByLevel(caches))
+
+	return caches
+}
+
+func readCPUCache(dir string) CPUCache {
+	return CPUCache{
+		Level:      sysfsInt(filepath.Join(dir, "level")),
+		Type:       cacheType(sysfsString(filepath.Join(dir, "type"))),
+		Size:       parseCacheSize(sysfsString(filepath.Join(dir, "size"))),
+		Ways:       sysfsInt(filepath.Join(dir, "ways_of_associativity")),
+		LineSize:   sysfsInt(filepath.Join(dir, "coherency_line_size")),
+		Sets:       sysfsInt(filepath.Join(dir, "number_of_sets")),
+		SharedCPUs: parseCPUList(sysfsString(filepath.Join(dir, "shared_cpu_list"))),
+	}
+}
+
+func cacheType(t string) string {
+	switch t {
+	case "Data":
+		return CacheData
+	case "Instruction":
+		return CacheInstruction
+	case "Unified":
+		return CacheUnified
+	}
+
+	return strings.ToLower(t)
+}
+
+// Parses sizes such as "32K" or "30M", returned in bytes
+func parseCacheSize(s string) uint64 {
+	mult := uint64(1)
+
+	switch {
+	case strings.HasSuffix(s, "K"):
+		mult = 1024
+	case strings.HasSuffix(s, "M"):
+		mult = 1024 * 1024
+	case strings.HasSuffix(s, "G"):
+		mult = 1024 * 1024 * 1024
+	}
+
+	v, err := strconv.ParseUint(strings.TrimRight(s, "KMG"), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return v * mult
+}
+
+func cpuListKey(cpus []int) string {
+	ids := make([]string, len(cpus))
+	for i, id := range cpus {
+		ids[i] = strconv.Itoa(id)
+	}
+
+	return strings.Join(ids, ",")
+}
+
+// ----
+
+type cpuCachesByLevel []CPUCache
+
+func (c cpuCachesByLevel) Len() int {
+	return len(c)
+}
+
+func (c cpuCachesByLevel) Swap(i, j int) {
+	c[i], c[j] = c[j], c[i]
+}
+
+func (c cpuCachesByLevel) Less(i, j int) bool {
+	if c[i].Level != c[j].Level {
+		return c[i].Level < c[j].Level
+	}
+
+	if c[i].Type != c[j].Type {
+		return c[i].Type < c[j].Type
+	}
+
+	return c[i].SharedCPUs[0] < c[j].SharedCPUs[0]
+}
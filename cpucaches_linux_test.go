@@ -0,0 +1,71 @@
+package libsysinfo
+
+import (
+	. "launchpad.net/gocheck"
+)
+
+type CPUCachesTestSuite struct{}
+
+var (
+	_ = Suite(&CPUCachesTestSuite{})
+)
+
+func cacheFixture(files map[string]string, cpu string, index string, level string, t string, size string, ways string, sets string, shared string) {
+	prefix := cpu + "/cache/" + index + "/"
+
+	files[prefix+"level"] = level + "\n"
+	files[prefix+"type"] = t + "\n"
+	files[prefix+"size"] = size + "\n"
+	files[prefix+"ways_of_associativity"] = ways + "\n"
+	files[prefix+"coherency_line_size"] = "64\n"
+	files[prefix+"number_of_sets"] = sets + "\n"
+	files[prefix+"shared_cpu_list"] = shared + "\n"
+}
+
+func (s *CPUCachesTestSuite) TestReadCPUCaches(c *C) {
+	root := c.MkDir()
+
+	files := map[string]string{
+		"online": "0-1\n",
+	}
+
+	cacheFixture(files, "cpu0", "index0", "1", "Data", "48K", "12", "64", "0")
+	cacheFixture(files, "cpu0", "index1", "1", "Instruction", "32K", "8", "64", "0")
+	cacheFixture(files, "cpu0", "index2", "2", "Unified", "1280K", "10", "2048", "0")
+	cacheFixture(files, "cpu0", "index3", "3", "Unified", "30M", "12", "40960", "0-1")
+	cacheFixture(files, "cpu1", "index0", "1", "Data", "48K", "12", "64", "1")
+	cacheFixture(files, "cpu1", "index1", "1", "Instruction", "32K", "8", "64", "1")
+	cacheFixture(files, "cpu1", "index2", "2", "Unified", "1280K", "10", "2048", "1")
+	cacheFixture(files, "cpu1", "index3", "3", "Unified", "30M", "12", "40960", "0-1")
+
+	writeFixtureFiles(c, root, files)
+
+	obtained := readCPUCaches(root)
+
+	expected := []CPUCache{
+		CPUCache{Level: 1, Type: CacheData, Size: 49152, Ways: 12, LineSize: 64, Sets: 64, SharedCPUs: []int{0}},
+		CPUCache{Level: 1, Type: CacheData, Size: 49152, Ways: 12, LineSize: 64, Sets: 64, SharedCPUs: []int{1}},
+		CPUCache{Level: 1, Type: CacheInstruction, Size: 32768, Ways: 8, LineSize: 64, Sets: 64, SharedCPUs: []int{0}},
+		CPUCache{Level: 1, Type: CacheInstruction, Size: 32768, Ways: 8, LineSize: 64, Sets: 64, SharedCPUs: []int{1}},
+		CPUCache{Level: 2, Type: CacheUnified, Size: 1310720, Ways: 10, LineSize: 64, Sets: 2048, SharedCPUs: []int{0}},
+		CPUCache{Level: 2, Type: CacheUnified, Size: 1310720, Ways: 10, LineSize: 64, Sets: 2048, SharedCPUs: []int{1}},
+		CPUCache{Level: 3, Type: CacheUnified, Size: 31457280, Ways: 12, LineSize: 64, Sets: 40960, SharedCPUs: []int{0, 1}},
+	}
+
+	c.Assert(obtained, DeepEquals, expected)
+}
+
+func (s *CPUCachesTestSuite) TestParseCacheSize(c *C) {
+	sizes := map[string]uint64{
+		"32K":   32768,
+		"30M":   31457280,
+		"1G":    1073741824,
+		"512":   512,
+		"":      0,
+		"bogus": 0,
+	}
+
+	for in, expected := range sizes {
+		c.Assert(parseCacheSize(in), Equals, expected)
+	}
+}
@@ -0,0 +1,378 @@
+package libsysinfo
+
+import (
+	"strings"
+)
+
+// A processor capability, as reported by the kernel in the flags (x86) or
+// Features (ARM64) line of /proc/cpuinfo
+type CPUFeature int
+
+const (
+	// x86
+	FeatureFPU CPUFeature = iota
+	FeatureTSC
+	FeatureCX8
+	FeatureCMOV
+	FeatureMMX
+	FeatureFXSR
+	FeatureSSE
+	FeatureSSE2
+	FeatureHT
+	FeatureSYSCALL
+	FeatureNX
+	FeaturePDPE1GB
+	FeatureRDTSCP
+	FeatureLM
+	FeatureConstantTSC
+	FeatureInvariantTSC
+	FeatureSSE3
+	FeaturePCLMULQDQ
+	FeatureVMX
+	FeatureSVM
+	FeatureSSSE3
+	FeatureFMA
+	FeatureCX16
+	FeatureSSE41
+	FeatureSSE42
+	FeatureMOVBE
+	FeaturePOPCNT
+	FeatureXSAVE
+	FeatureAVX
+	FeatureF16C
+	FeatureRDRAND
+	FeatureHypervisor
+	FeatureLAHFLM
+	FeatureLZCNT
+	FeatureBMI1
+	FeatureAVX2
+	FeatureBMI2
+	FeatureERMS
+	FeatureAVX512F
+	FeatureAVX512DQ
+	FeatureRDSEED
+	FeatureADX
+	FeatureCLFLUSHOPT
+	FeatureCLWB
+	FeatureAVX512CD
+	FeatureSHA
+	FeatureAVX512BW
+	FeatureAVX512VL
+	FeatureAVX512VNNI
+	FeatureAVX512BF16
+	FeatureAVXVNNI
+	FeatureGFNI
+	FeatureVAES
+	FeatureVPCLMULQDQ
+	FeatureFSRM
+	FeatureAMXBF16
+	FeatureAMXTile
+	FeatureAMXInt8
+
+	// x86 and ARM64
+	FeatureAES
+
+	// ARM64
+	FeatureFP
+	FeatureASIMD
+	FeaturePMULL
+	FeatureSHA1
+	FeatureSHA2
+	FeatureSHA3
+	FeatureSHA512
+	FeatureCRC32
+	FeatureAtomics
+	FeatureFPHP
+	FeatureASIMDHP
+	FeatureASIMDRDM
+	FeatureASIMDDP
+	FeatureJSCVT
+	FeatureLRCPC
+	FeatureSM3
+	FeatureSM4
+	FeatureSVE
+	FeatureSVE2
+	FeatureI8MM
+	FeatureBF16
+	FeatureRNG
+	FeatureBTI
+	FeatureMTE
+	FeaturePACA
+	FeaturePACG
+
+	cpuFeatureCount
+)
+
+// x86-64 microarchitecture levels as defined by the x86-64 psABI
+type X86Level int
+
+const (
+	// Not an x86-64 processor, or a level could not be determined
+	X86LevelNone X86Level = iota
+	X86LevelV1
+	X86LevelV2
+	X86LevelV3
+	X86LevelV4
+)
+
+const (
+	cpuFeatureWords = (int(cpuFeatureCount) + 63) / 64
+)
+
+var (
+	// Names of the features, as printed by the kernel
+	cpuFeatureNames = [cpuFeatureCount]string{
+		FeatureFPU:          "fpu",
+		FeatureTSC:          "tsc",
+		FeatureCX8:          "cx8",
+		FeatureCMOV:         "cmov",
+		FeatureMMX:          "mmx",
+		FeatureFXSR:         "fxsr",
+		FeatureSSE:          "sse",
+		FeatureSSE2:         "sse2",
+		FeatureHT:           "ht",
+		FeatureSYSCALL:      "syscall",
+		FeatureNX:           "nx",
+		FeaturePDPE1GB:      "pdpe1gb",
+		FeatureRDTSCP:       "rdtscp",
+		FeatureLM:           "lm",
+		FeatureConstantTSC:  "constant_tsc",
+		FeatureInvariantTSC: "nonstop_tsc",
+		FeatureSSE3:         "pni",
+		FeaturePCLMULQDQ:    "pclmulqdq",
+		FeatureVMX:          "vmx",
+		FeatureSVM:          "svm",
+		FeatureSSSE3:        "ssse3",
+		FeatureFMA:          "fma",
+		FeatureCX16:         "cx16",
+		FeatureSSE41:        "sse4_1",
+		FeatureSSE42:        "sse4_2",
+		FeatureMOVBE:        "movbe",
+		FeaturePOPCNT:       "popcnt",
+		FeatureXSAVE:        "xsave",
+		FeatureAVX:          "avx",
+		FeatureF16C:         "f16c",
+		FeatureRDRAND:       "rdrand",
+		FeatureHypervisor:   "hypervisor",
+		FeatureLAHFLM:       "lahf_lm",
+		FeatureLZCNT:        "abm",
+		FeatureBMI1:         "bmi1",
+		FeatureAVX2:         "avx2",
+		FeatureBMI2:         "bmi2",
+		FeatureERMS:         "erms",
+		FeatureAVX512F:      "avx512f",
+		FeatureAVX512DQ:     "avx512dq",
+		FeatureRDSEED:       "rdseed",
+		FeatureADX:          "adx",
+		FeatureCLFLUSHOPT:   "clflushopt",
+		FeatureCLWB:         "clwb",
+		FeatureAVX512CD:     "avx512cd",
+		FeatureSHA:          "sha_ni",
+		FeatureAVX512BW:     "avx512bw",
+		FeatureAVX512VL:     "avx512vl",
+		FeatureAVX512VNNI:   "avx512_vnni",
+		FeatureAVX512BF16:   "avx512_bf16",
+		FeatureAVXVNNI:      "avx_vnni",
+		FeatureGFNI:         "gfni",
+		FeatureVAES:         "vaes",
+		FeatureVPCLMULQDQ:   "vpclmulqdq",
+		FeatureFSRM:         "fsrm",
+		FeatureAMXBF16:      "amx_bf16",
+		FeatureAMXTile:      "amx_tile",
+		FeatureAMXInt8:      "amx_int8",
+
+		FeatureAES: "aes",
+
+		FeatureFP:       "fp",
+		FeatureASIMD:    "asimd",
+		FeaturePMULL:    "pmull",
+		FeatureSHA1:     "sha1",
+		FeatureSHA2:     "sha2",
+		FeatureSHA3:     "sha3",
+		FeatureSHA512:   "sha512",
+		FeatureCRC32:    "crc32",
+		FeatureAtomics:  "atomics",
+		FeatureFPHP:     "fphp",
+		FeatureASIMDHP:  "asimdhp",
+		FeatureASIMDRDM: "asimdrdm",
+		FeatureASIMDDP:  "asimddp",
+		FeatureJSCVT:    "jscvt",
+		FeatureLRCPC:    "lrcpc",
+		FeatureSM3:      "sm3",
+		FeatureSM4:      "sm4",
+		FeatureSVE:      "sve",
+		FeatureSVE2:     "sve2",
+		FeatureI8MM:     "i8mm",
+		FeatureBF16:     "bf16",
+		FeatureRNG:      "rng",
+		FeatureBTI:      "bti",
+		FeatureMTE:      "mte",
+		FeaturePACA:     "paca",
+		FeaturePACG:     "pacg",
+	}
+
+	cpuFeaturesByName = make(map[string]CPUFeature, cpuFeatureCount)
+
+	x86LevelFeatures = map[X86Level][]CPUFeature{
+		X86LevelV1: []CPUFeature{
+			FeatureLM, FeatureCMOV, FeatureCX8, FeatureFPU, FeatureFXSR,
+			FeatureMMX, FeatureSYSCALL, FeatureSSE, FeatureSSE2,
+		},
+		X86LevelV2: []CPUFeature{
+			FeatureCX16, FeatureLAHFLM, FeaturePOPCNT, FeatureSSE3,
+			FeatureSSE41, FeatureSSE42, FeatureSSSE3,
+		},
+		X86LevelV3: []CPUFeature{
+			FeatureAVX, FeatureAVX2, FeatureBMI1, FeatureBMI2, FeatureF16C,
+			FeatureFMA, FeatureLZCNT, FeatureMOVBE, FeatureXSAVE,
+		},
+		X86LevelV4: []CPUFeature{
+			FeatureAVX512F, FeatureAVX512BW, FeatureAVX512CD,
+			FeatureAVX512DQ, FeatureAVX512VL,
+		},
+	}
+)
+
+func init() {
+	for f, name := range cpuFeatureNames {
+		cpuFeaturesByName[name] = CPUFeature(f)
+	}
+
+	// alternative names used by some kernels
+	cpuFeaturesByName["sse3"] = FeatureSSE3
+	cpuFeaturesByName["lzcnt"] = FeatureLZCNT
+}
+
+func (f CPUFeature) String() string {
+	if f < 0 || f >= cpuFeatureCount {
+		return "unknown"
+	}
+
+	return cpuFeatureNames[f]
+}
+
+func (l X86Level) String() string {
+	if l <= X86LevelNone || l > X86LevelV4 {
+		return "none"
+	}
+
+	return "x86-64-v" + string(rune('0'+int(l)))
+}
+
+// ----
+
+// A set of processor capabilities
+type CPUFeatures [cpuFeatureWords]uint64
+
+func (fs CPUFeatures) Has(f CPUFeature) bool {
+	if f < 0 || f >= cpuFeatureCount {
+		return false
+	}
+
+	return fs[f/64]&(1<<uint(f%64)) != 0
+}
+
+// Reports whether every feature is present
+func (fs CPUFeatures) HasAll(features ...CPUFeature) bool {
+	for _, f := range features {
+		if !fs.Has(f) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Reports whether at least one of the features is present
+func (fs CPUFeatures) HasAny(features ...CPUFeature) bool {
+	for _, f := range features {
+		if fs.Has(f) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (fs *CPUFeatures) Set(f CPUFeature) {
+	if f < 0 || f >= cpuFeatureCount {
+		return
+	}
+
+	fs[f/64] |= 1 << uint(f%64)
+}
+
+// Returns the features present in both sets
+func (fs CPUFeatures) Intersect(other CPUFeatures) CPUFeatures {
+	var out CPUFeatures
+
+	for i := range fs {
+		out[i] = fs[i] & other[i]
+	}
+
+	return out
+}
+
+// Returns the features of the set, in the order of their constants
+func (fs CPUFeatures) List() []CPUFeature {
+	var features []CPUFeature
+
+	for f := CPUFeature(0); f < cpuFeatureCount; f++ {
+		if fs.Has(f) {
+			features = append(features, f)
+		}
+	}
+
+	return features
+}
+
+// Returns the highest x86-64 microarchitecture level the features satisfy
+func (fs CPUFeatures) X86Level() X86Level {
+	level := X86LevelNone
+
+	for l := X86LevelV1; l <= X86LevelV4; l++ {
+		if !fs.HasAll(x86LevelFeatures[l]...) {
+			break
+		}
+
+		level = l
+	}
+
+	return level
+}
+
+func (fs CPUFeatures) String() string {
+	var names []string
+
+	for _, f := range fs.List() {
+		names = append(names, f.String())
+	}
+
+	return strings.Join(names, " ")
+}
+
+// ----
+
+func newCPUFeatures(features ...CPUFeature) CPUFeatures {
+	var fs CPUFeatures
+
+	for _, f := range features {
+		fs.Set(f)
+	}
+
+	return fs
+}
+
+// Builds a set from kernel flag names, unknown flags are ignored
+func parseCPUFeatures(flags []string) CPUFeatures {
+	var fs CPUFeatures
+
+	for _, flag := range flags {
+		f, exists := cpuFeaturesByName[flag]
+		if exists {
+			fs.Set(f)
+		}
+	}
+
+	return fs
+}
@@ -0,0 +1,105 @@
+package libsysinfo
+
+import (
+	. "launchpad.net/gocheck"
+)
+
+type CPUFeaturesTestSuite struct{}
+
+var (
+	_ = Suite(&CPUFeaturesTestSuite{})
+
+	// Features recognized in the flags of the cpuinfo fixture
+	pentiumG630TFeatures = newCPUFeatures(
+		FeatureFPU, FeatureTSC, FeatureCX8, FeatureCMOV, FeatureMMX,
+		FeatureFXSR, FeatureSSE, FeatureSSE2, FeatureHT, FeatureSYSCALL,
+		FeatureNX, FeatureRDTSCP, FeatureLM, FeatureConstantTSC,
+		FeatureInvariantTSC, FeatureSSE3, FeaturePCLMULQDQ, FeatureVMX,
+		FeatureSSSE3, FeatureCX16, FeatureSSE41, FeatureSSE42,
+		FeaturePOPCNT, FeatureXSAVE, FeatureLAHFLM,
+	)
+)
+
+func (s *CPUFeaturesTestSuite) TestParseCPUFeatures(c *C) {
+	fs := parseCPUFeatures([]string{"fpu", "sse3", "avx2", "lzcnt", "bogus", ""})
+
+	c.Assert(fs.List(), DeepEquals, []CPUFeature{FeatureFPU, FeatureSSE3, FeatureLZCNT, FeatureAVX2})
+	c.Assert(fs.String(), Equals, "fpu pni abm avx2")
+}
+
+func (s *CPUFeaturesTestSuite) TestHasAllHasAny(c *C) {
+	fs := newCPUFeatures(FeatureAES, FeatureAVX2, FeatureASIMD)
+
+	c.Assert(fs.Has(FeatureAES), Equals, true)
+	c.Assert(fs.Has(FeatureSHA), Equals, false)
+	c.Assert(fs.Has(cpuFeatureCount), Equals, false)
+
+	c.Assert(fs.HasAll(FeatureAES, FeatureAVX2), Equals, true)
+	c.Assert(fs.HasAll(FeatureAES, FeatureSHA), Equals, false)
+	c.Assert(fs.HasAll(), Equals, true)
+
+	c.Assert(fs.HasAny(FeatureSHA, FeatureASIMD), Equals, true)
+	c.Assert(fs.HasAny(FeatureSHA, FeatureSVE), Equals, false)
+	c.Assert(fs.HasAny(), Equals, false)
+}
+
+func (s *CPUFeaturesTestSuite) TestIntersect(c *C) {
+	a := newCPUFeatures(FeatureAES, FeatureAVX2, FeatureSVE2)
+	b := newCPUFeatures(FeatureAES, FeatureSVE2, FeatureSHA)
+
+	c.Assert(a.Intersect(b), Equals, newCPUFeatures(FeatureAES, FeatureSVE2))
+}
+
+func (s *CPUFeaturesTestSuite) TestX86Level(c *C) {
+	c.Assert(CPUFeatures{}.X86Level(), Equals, X86LevelNone)
+	c.Assert(pentiumG630TFeatures.X86Level(), Equals, X86LevelV2)
+	c.Assert(pentiumG630TFeatures.X86Level().String(), Equals, "x86-64-v2")
+
+	v3 := parseCPUFeatures([]string{
+		"fpu", "cx8", "cmov", "mmx", "fxsr", "sse", "sse2", "syscall", "lm",
+		"cx16", "lahf_lm", "popcnt", "pni", "sse4_1", "sse4_2", "ssse3",
+		"avx", "avx2", "bmi1", "bmi2", "f16c", "fma", "abm", "movbe", "xsave",
+		"avx512f", "avx512bw",
+	})
+	c.Assert(v3.X86Level(), Equals, X86LevelV3)
+
+	v4 := v3
+	for _, f := range []CPUFeature{FeatureAVX512CD, FeatureAVX512DQ, FeatureAVX512VL} {
+		v4.Set(f)
+	}
+	c.Assert(v4.X86Level(), Equals, X86LevelV4)
+	c.Assert(X86LevelNone.String(), Equals, "none")
+}
+
+func (s *CPUFeaturesTestSuite) TestProcessCpuInfos_ARM64(c *C) {
+	fixtures := `processor	: 0
+BogoMIPS	: 243.75
+Features	: fp asimd evtstrm aes pmull sha1 sha2 crc32 atomics fphp asimdhp cpuid asimdrdm lrcpc dcpop asimddp
+CPU implementer	: 0x41
+
+`
+	obtained := processCpuInfos(fixtures)
+
+	c.Assert(len(obtained), Equals, 1)
+	c.Assert(obtained[0].Features, Equals, newCPUFeatures(
+		FeatureFP, FeatureASIMD, FeatureAES, FeaturePMULL, FeatureSHA1,
+		FeatureSHA2, FeatureCRC32, FeatureAtomics, FeatureFPHP,
+		FeatureASIMDHP, FeatureASIMDRDM, FeatureLRCPC, FeatureASIMDDP,
+	))
+	c.Assert(obtained[0].Features.X86Level(), Equals, X86LevelNone)
+}
+
+func (s *CPUFeaturesTestSuite) TestProcessCpuInfos_BugsAndVMXFlags(c *C) {
+	fixtures := `processor	: 0
+flags		: fpu vmx
+vmx flags	: vnmi preemption_timer invvpid ept_x_only ept_ad
+bugs		: spectre_v1 spectre_v2 spec_store_bypass swapgs
+
+`
+	obtained := processCpuInfos(fixtures)
+
+	c.Assert(len(obtained), Equals, 1)
+	c.Assert(obtained[0].VMXFlags, DeepEquals, []string{"vnmi", "preemption_timer", "invvpid", "ept_x_only", "ept_ad"})
+	c.Assert(obtained[0].Bugs, DeepEquals, []string{"spectre_v1", "spectre_v2", "spec_store_bypass", "swapgs"})
+	c.Assert(obtained[0].Features, Equals, newCPUFeatures(FeatureFPU, FeatureVMX))
+}
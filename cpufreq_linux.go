@@ -0,0 +1,288 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	sysCPUPath = "/sys/devices/system/cpu"
+)
+
+var (
+	ErrCPUFreqNotAvailable = &LibSysInfoErr{"CPU frequency informations not available"}
+)
+
+// ----
+
+// Frequency scaling and idle states of a CPU. Frequencies are in kHz and
+// are zero when the CPU has no cpufreq driver, e.g. in most virtual machines.
+type CPUFrequency struct {
+	CPU int
+
+	// e.g. intel_pstate, amd-pstate-epp, acpi-cpufreq or cppc_cpufreq
+	Driver string
+
+	Governor           string
+	AvailableGovernors []string
+
+	// Current scaling limits
+	MinFreq uint64
+	MaxFreq uint64
+
+	// Hardware limits
+	HardwareMinFreq uint64
+	HardwareMaxFreq uint64
+
+	// Frequency requested by the governor, or the one reported by the
+	// hardware when the driver does not track it
+	CurFreq uint64
+
+	// Only set by drivers supporting hardware P-states, e.g.
+	// balance_performance
+	EnergyPerformancePreference           string
+	AvailableEnergyPerformancePreferences []string
+
+	IdleStates []CPUIdleState
+}
+
+// A cpuidle state. Times are in microseconds.
+type CPUIdleState struct {
+	// e.g. POLL, C1, C1E or C6
+	Name        string
+	Description string
+
+	// Exit latency
+	Latency uint64
+
+	// Minimum time to spend in the state for it to be worth entering
+	TargetResidency uint64
+
+	// Number of times the state was entered
+	Usage uint64
+
+	// Total time spent in the state
+	Time uint64
+
+	Disabled bool
+}
+
+type CPUFreqInfo struct {
+	CPUs []CPUFrequency
+
+	// The driver and governor of the first CPU, drivers are system wide
+	// while governors may differ between CPUs
+	Driver   string
+	Governor string
+
+	// Number of CPUs using each governor
+	Governors map[string]int
+
+	// Lowest and highest current scaling limits across CPUs, in kHz
+	MinFreq uint64
+	MaxFreq uint64
+
+	// Average current frequency across CPUs, in kHz
+	AvgFreq uint64
+
+	// Set when the driver exposes a boost (turbo) switch
+	BoostSupported bool
+	Boost          bool
+
+	IdleDriver   string
+	IdleGovernor string
+
+	// Idle states with Usage and Time summed across CPUs. A state is
+	// Disabled only when disabled on every CPU.
+	IdleStates []CPUIdleState
+}
+
+// ----
+
+// Returns the frequency scaling and idle states of every online CPU
+func CPUFreq() (CPUFreqInfo, error) {
+	_, err := os.Stat(sysCPUPath)
+	if err != nil {
+		return CPUFreqInfo{}, err
+	}
+
+	info := readCPUFreq(sysCPUPath)
+	if info.Driver == "" && info.IdleDriver == "" {
+		return info, ErrCPUFreqNotAvailable
+	}
+
+	return info, nil
+}
+
+// ----
+
+func readCPUFreq(root string) CPUFreqInfo {
+	var info CPUFreqInfo
+
+	for _, id := range onlineCPUs(root) {
+		info.CPUs = append(info.CPUs, readCPUFrequency(root, id))
+	}
+
+	info.Governors = make(map[string]int)
+
+	var total uint64
+	for i, cf := range info.CPUs {
+		if cf.Governor != "" {
+			info.Governors[cf.Governor]++
+		}
+
+		if i == 0 {
+			info.Driver = cf.Driver
+			info.Governor = cf.Governor
+			info.MinFreq = cf.MinFreq
+			info.MaxFreq = cf.MaxFreq
+		}
+
+		if cf.MinFreq < info.MinFreq {
+			info.MinFreq = cf.MinFreq
+		}
+
+		if cf.MaxFreq > info.MaxFreq {
+			info.MaxFreq = cf.MaxFreq
+		}
+
+		total += cf.CurFreq
+	}
+
+	if len(info.CPUs) > 0 {
+		info.AvgFreq = total / uint64(len(info.CPUs))
+	}
+
+	info.BoostSupported, info.Boost = readCPUBoost(root)
+
+	// "none" when cpuidle is built in but no driver is registered
+	info.IdleDriver = sysfsString(filepath.Join(root, "cpuidle", "current_driver"))
+	if info.IdleDriver == "none" {
+		info.IdleDriver = ""
+	}
+
+	info.IdleGovernor = sysfsString(filepath.Join(root, "cpuidle", "current_governor_ro"))
+	if info.IdleGovernor == "" {
+		// current_governor replaces it when governors can be switched
+		info.IdleGovernor = sysfsString(filepath.Join(root, "cpuidle", "current_governor"))
+	}
+
+	info.IdleStates = sumIdleStates(info.CPUs)
+
+	return info
+}
+
+// Returns the online CPUs ids, sorted
+func onlineCPUs(root string) []int {
+	online := parseCPUList(sysfsString(filepath.Join(root, "online")))
+	if len(online) > 0 {
+		return online
+	}
+
+	for _, name := range dirNames(root) {
+		id, err := strconv.Atoi(strings.TrimPrefix(name, "cpu"))
+		if strings.HasPrefix(name, "cpu") && err == nil {
+			online = append(online, id)
+		}
+	}
+
+	sort.Ints(online)
+
+	return online
+}
+
+func readCPUFrequency(root string, id int) CPUFrequency {
+	dir := filepath.Join(root, "cpu"+strconv.Itoa(id))
+	freqDir := filepath.Join(dir, "cpufreq")
+
+	cf := CPUFrequency{
+		CPU:                         id,
+		Driver:                      sysfsString(filepath.Join(freqDir, "scaling_driver")),
+		Governor:                    sysfsString(filepath.Join(freqDir, "scaling_governor")),
+		AvailableGovernors:          strings.Fields(sysfsString(filepath.Join(freqDir, "scaling_available_governors"))),
+		MinFreq:                     sysfsUint64(filepath.Join(freqDir, "scaling_min_freq")),
+		MaxFreq:                     sysfsUint64(filepath.Join(freqDir, "scaling_max_freq")),
+		HardwareMinFreq:             sysfsUint64(filepath.Join(freqDir, "cpuinfo_min_freq")),
+		HardwareMaxFreq:             sysfsUint64(filepath.Join(freqDir, "cpuinfo_max_freq")),
+		CurFreq:                     sysfsUint64(filepath.Join(freqDir, "scaling_cur_freq")),
+		EnergyPerformancePreference: sysfsString(filepath.Join(freqDir, "energy_performance_preference")),
+		AvailableEnergyPerformancePreferences: strings.Fields(
+			sysfsString(filepath.Join(freqDir, "energy_performance_available_preferences")),
+		),
+	}
+
+	if cf.CurFreq == 0 {
+		cf.CurFreq = sysfsUint64(filepath.Join(freqDir, "cpuinfo_cur_freq"))
+	}
+
+	cf.IdleStates = readIdleStates(filepath.Join(dir, "cpuidle"))
+
+	return cf
+}
+
+func readIdleStates(dir string) []CPUIdleState {
+	var states []CPUIdleState
+
+	// states are numbered from 0 without gaps
+	for i := 0; ; i++ {
+		stateDir := filepath.Join(dir, "state"+strconv.Itoa(i))
+
+		_, err := os.Stat(stateDir)
+		if err != nil {
+			break
+		}
+
+		states = append(states, CPUIdleState{
+			Name:            sysfsString(filepath.Join(stateDir, "name")),
+			Description:     sysfsString(filepath.Join(stateDir, "desc")),
+			Latency:         sysfsUint64(filepath.Join(stateDir, "latency")),
+			TargetResidency: sysfsUint64(filepath.Join(stateDir, "residency")),
+			Usage:           sysfsUint64(filepath.Join(stateDir, "usage")),
+			Time:            sysfsUint64(filepath.Join(stateDir, "time")),
+			Disabled:        sysfsBool(filepath.Join(stateDir, "disable")),
+		})
+	}
+
+	return states
+}
+
+// Returns whether a boost switch is available and its state
+func readCPUBoost(root string) (bool, bool) {
+	// acpi-cpufreq and amd-pstate
+	boost := sysfsString(filepath.Join(root, "cpufreq", "boost"))
+	if boost != "" {
+		return true, boost == "1"
+	}
+
+	// intel_pstate exposes the opposite setting
+	noTurbo := sysfsString(filepath.Join(root, "intel_pstate", "no_turbo"))
+	if noTurbo != "" {
+		return true, noTurbo == "0"
+	}
+
+	return false, false
+}
+
+func sumIdleStates(cpus []CPUFrequency) []CPUIdleState {
+	var states []CPUIdleState
+
+	for _, cf := range cpus {
+		for i, st := range cf.IdleStates {
+			if i >= len(states) {
+				states = append(states, st)
+				continue
+			}
+
+			states[i].Usage += st.Usage
+			states[i].Time += st.Time
+			states[i].Disabled = states[i].Disabled && st.Disabled
+		}
+	}
+
+	return states
+}
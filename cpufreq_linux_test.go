@@ -0,0 +1,115 @@
+package libsysinfo
+
+import (
+	. "launchpad.net/gocheck"
+)
+
+type CPUFreqTestSuite struct{}
+
+var (
+	_ = Suite(&CPUFreqTestSuite{})
+)
+
+func (s *CPUFreqTestSuite) TestReadCPUFreq(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"online":                                                "0-1\n",
+		"intel_pstate/no_turbo":                                 "0\n",
+		"cpuidle/current_driver":                                "intel_idle\n",
+		"cpuidle/current_governor_ro":                           "menu\n",
+		"cpu0/cpufreq/scaling_driver":                           "intel_pstate\n",
+		"cpu0/cpufreq/scaling_governor":                         "powersave\n",
+		"cpu0/cpufreq/scaling_available_governors":              "performance powersave\n",
+		"cpu0/cpufreq/scaling_min_freq":                         "800000\n",
+		"cpu0/cpufreq/scaling_max_freq":                         "4200000\n",
+		"cpu0/cpufreq/cpuinfo_min_freq":                         "400000\n",
+		"cpu0/cpufreq/cpuinfo_max_freq":                         "4700000\n",
+		"cpu0/cpufreq/scaling_cur_freq":                         "1200000\n",
+		"cpu0/cpufreq/energy_performance_preference":            "balance_performance\n",
+		"cpu0/cpufreq/energy_performance_available_preferences": "default performance balance_performance balance_power power\n",
+		"cpu0/cpuidle/state0/name":                              "POLL\n",
+		"cpu0/cpuidle/state0/desc":                              "CPUIDLE CORE POLL IDLE\n",
+		"cpu0/cpuidle/state0/latency":                           "0\n",
+		"cpu0/cpuidle/state0/residency":                         "0\n",
+		"cpu0/cpuidle/state0/usage":                             "100\n",
+		"cpu0/cpuidle/state0/time":                              "2000\n",
+		"cpu0/cpuidle/state0/disable":                           "0\n",
+		"cpu0/cpuidle/state1/name":                              "C6\n",
+		"cpu0/cpuidle/state1/desc":                              "MWAIT 0x20\n",
+		"cpu0/cpuidle/state1/latency":                           "85\n",
+		"cpu0/cpuidle/state1/residency":                         "200\n",
+		"cpu0/cpuidle/state1/usage":                             "5000\n",
+		"cpu0/cpuidle/state1/time":                              "900000\n",
+		"cpu0/cpuidle/state1/disable":                           "1\n",
+		"cpu1/cpufreq/scaling_driver":                           "intel_pstate\n",
+		"cpu1/cpufreq/scaling_governor":                         "performance\n",
+		"cpu1/cpufreq/scaling_min_freq":                         "1000000\n",
+		"cpu1/cpufreq/scaling_max_freq":                         "4700000\n",
+		"cpu1/cpufreq/cpuinfo_cur_freq":                         "3000000\n",
+		"cpu1/cpuidle/state0/name":                              "POLL\n",
+		"cpu1/cpuidle/state0/usage":                             "50\n",
+		"cpu1/cpuidle/state0/time":                              "1000\n",
+		"cpu1/cpuidle/state1/name":                              "C6\n",
+		"cpu1/cpuidle/state1/usage":                             "10\n",
+		"cpu1/cpuidle/state1/time":                              "500\n",
+		"cpu1/cpuidle/state1/disable":                           "0\n",
+		"cpu2/cpufreq/scaling_driver":                           "intel_pstate\n",
+	})
+
+	obtained := readCPUFreq(root)
+
+	c.Assert(len(obtained.CPUs), Equals, 2)
+
+	c.Assert(obtained.CPUs[0], DeepEquals, CPUFrequency{
+		CPU:                         0,
+		Driver:                      "intel_pstate",
+		Governor:                    "powersave",
+		AvailableGovernors:          []string{"performance", "powersave"},
+		MinFreq:                     800000,
+		MaxFreq:                     4200000,
+		HardwareMinFreq:             400000,
+		HardwareMaxFreq:             4700000,
+		CurFreq:                     1200000,
+		EnergyPerformancePreference: "balance_performance",
+		AvailableEnergyPerformancePreferences: []string{
+			"default", "performance", "balance_performance", "balance_power", "power",
+		},
+		IdleStates: []CPUIdleState{
+			CPUIdleState{Name: "POLL", Description: "CPUIDLE CORE POLL IDLE", Usage: 100, Time: 2000},
+			CPUIdleState{Name: "C6", Description: "MWAIT 0x20", Latency: 85, TargetResidency: 200, Usage: 5000, Time: 900000, Disabled: true},
+		},
+	})
+
+	c.Assert(obtained.CPUs[1].CurFreq, Equals, uint64(3000000))
+
+	c.Assert(obtained.Driver, Equals, "intel_pstate")
+	c.Assert(obtained.Governor, Equals, "powersave")
+	c.Assert(obtained.Governors, DeepEquals, map[string]int{"powersave": 1, "performance": 1})
+	c.Assert(obtained.MinFreq, Equals, uint64(800000))
+	c.Assert(obtained.MaxFreq, Equals, uint64(4700000))
+	c.Assert(obtained.AvgFreq, Equals, uint64(2100000))
+	c.Assert(obtained.BoostSupported, Equals, true)
+	c.Assert(obtained.Boost, Equals, true)
+	c.Assert(obtained.IdleDriver, Equals, "intel_idle")
+	c.Assert(obtained.IdleGovernor, Equals, "menu")
+
+	c.Assert(obtained.IdleStates, DeepEquals, []CPUIdleState{
+		CPUIdleState{Name: "POLL", Description: "CPUIDLE CORE POLL IDLE", Usage: 150, Time: 3000},
+		CPUIdleState{Name: "C6", Description: "MWAIT 0x20", Latency: 85, TargetResidency: 200, Usage: 5010, Time: 900500},
+	})
+}
+
+func (s *CPUFreqTestSuite) TestOnlineCPUs_NoOnlineFile(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"cpu0/uevent":    "",
+		"cpu2/uevent":    "",
+		"cpu10/uevent":   "",
+		"cpufreq/boost":  "1\n",
+		"cpuidle/uevent": "",
+	})
+
+	c.Assert(onlineCPUs(root), DeepEquals, []int{0, 2, 10})
+}
@@ -0,0 +1,574 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"strings"
+)
+
+const (
+	VendorIntel = "GenuineIntel"
+	VendorAMD   = "AuthenticAMD"
+	VendorHygon = "HygonGenuine"
+)
+
+const (
+	cpuidExtendedBase   = 0x80000000
+	cpuidHypervisorBase = 0x40000000
+
+	// XCR0 bits telling the OS saves the registers on context switches
+	xcr0SSE    = 1 << 1
+	xcr0AVX    = 1 << 2
+	xcr0AVX512 = 7 << 5
+	xcr0AMX    = 3 << 17
+)
+
+var (
+	ErrCPUIDNotAvailable = &LibSysInfoErr{"CPUID instruction not available"}
+
+	// Associativity of the AMD L2/L3 caches and TLBs, indexed by the 4 bits
+	// value found in leaves 0x80000006. 0 is unknown, -1 is fully
+	// associative.
+	amdAssociativity = [16]int{0, 1, 2, 3, 4, 6, 8, 0, 16, 0, 32, 48, 64, 96, 128, -1}
+)
+
+// ----
+
+// Processor identification read with the CPUID instruction, on the CPU the
+// calling goroutine runs on
+type CPUIDInfo struct {
+	Vendor string
+	Brand  string
+
+	// Highest basic and extended leaves
+	MaxLeaf         uint32
+	MaxExtendedLeaf uint32
+
+	// Display values, with the extended family and model fields combined
+	// as documented by Intel and AMD
+	Family   int
+	Model    int
+	Stepping int
+
+	// Set when running under a hypervisor, HypervisorVendor is e.g.
+	// KVMKVMKVM, Microsoft Hv, VMwareVMware or XenVMMXenVMM
+	Hypervisor       bool
+	HypervisorVendor string
+
+	Caches []CPUIDCache
+	TLBs   []CPUIDTLB
+
+	// Raw cache and TLB descriptors of leaf 2 (Intel only). 0xff means the
+	// caches are described by leaf 4 instead.
+	Descriptors []uint8
+
+	Leaves CPUIDLeaves
+
+	// Decoded from the feature leaves. Features needing operating system
+	// support (AVX, AVX-512, AMX) are only set when enabled in XCR0.
+	Features CPUFeatures
+}
+
+// Raw registers of the feature leaves, zero when the leaf is not supported
+type CPUIDLeaves struct {
+	Leaf1ECX uint32
+	Leaf1EDX uint32
+
+	Leaf7EBX  uint32
+	Leaf7ECX  uint32
+	Leaf7EDX  uint32
+	Leaf71EAX uint32
+
+	Ext1ECX uint32
+	Ext1EDX uint32
+	Ext7EDX uint32
+}
+
+// A cache described by the deterministic cache parameters leaf (4 on Intel,
+// 0x8000001d on AMD), or by the legacy AMD leaves. Sizes are in bytes.
+type CPUIDCache struct {
+	Level int
+
+	// One of CacheData, CacheInstruction or CacheUnified
+	Type string
+
+	Size     uint64
+	Ways     int
+	LineSize int
+	Sets     int
+
+	// Maximum number of logical processors sharing the cache, zero when
+	// not reported
+	SharedThreads int
+}
+
+type CPUIDTLB struct {
+	Level int
+
+	// One of CacheData, CacheInstruction or CacheUnified
+	Type string
+
+	// Supported page sizes in bytes
+	PageSizes []uint64
+
+	Entries int
+
+	// -1 for fully associative TLBs
+	Ways int
+}
+
+type cpuidFunc func(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+// Features reported by the bits of a register
+type cpuidBits struct {
+	reg      uint32
+	features map[uint]CPUFeature
+}
+
+// ----
+
+// Identifies the processor without relying on /proc/cpuinfo. Only supported
+// on amd64.
+func CPUID() (CPUIDInfo, error) {
+	if !cpuidSupported {
+		return CPUIDInfo{}, ErrCPUIDNotAvailable
+	}
+
+	var xcr0 uint64
+
+	_, _, ecx, _ := cpuid(1, 0)
+	if ecx&(1<<27) != 0 {
+		// OSXSAVE, xgetbv is available
+		eax, edx := xgetbv()
+		xcr0 = uint64(edx)<<32 | uint64(eax)
+	}
+
+	return readCPUID(cpuid, xcr0), nil
+}
+
+// ----
+
+func readCPUID(id cpuidFunc, xcr0 uint64) CPUIDInfo {
+	var info CPUIDInfo
+
+	eax, ebx, ecx, edx := id(0, 0)
+	info.MaxLeaf = eax
+	info.Vendor = cpuidString(ebx, edx, ecx)
+
+	info.MaxExtendedLeaf, _, _, _ = id(cpuidExtendedBase, 0)
+	if info.MaxExtendedLeaf < cpuidExtendedBase {
+		info.MaxExtendedLeaf = 0
+	}
+
+	leaf := func(l uint32, sub uint32) (uint32, uint32, uint32, uint32) {
+		if l >= cpuidExtendedBase && l > info.MaxExtendedLeaf {
+			return 0, 0, 0, 0
+		}
+		if l < cpuidExtendedBase && l > info.MaxLeaf {
+			return 0, 0, 0, 0
+		}
+
+		return id(l, sub)
+	}
+
+	eax, _, info.Leaves.Leaf1ECX, info.Leaves.Leaf1EDX = leaf(1, 0)
+	info.Family, info.Model, info.Stepping = cpuidSignature(info.Vendor, eax)
+
+	var maxSubleaf7 uint32
+	maxSubleaf7, info.Leaves.Leaf7EBX, info.Leaves.Leaf7ECX, info.Leaves.Leaf7EDX = leaf(7, 0)
+	if maxSubleaf7 >= 1 {
+		info.Leaves.Leaf71EAX, _, _, _ = leaf(7, 1)
+	}
+
+	_, _, info.Leaves.Ext1ECX, info.Leaves.Ext1EDX = leaf(cpuidExtendedBase+1, 0)
+	_, _, _, info.Leaves.Ext7EDX = leaf(cpuidExtendedBase+7, 0)
+
+	if info.MaxExtendedLeaf >= cpuidExtendedBase+4 {
+		var brand []uint32
+		for l := uint32(cpuidExtendedBase + 2); l <= cpuidExtendedBase+4; l++ {
+			a, b, c, d := leaf(l, 0)
+			brand = append(brand, a, b, c, d)
+		}
+
+		info.Brand = cpuidString(brand...)
+	}
+
+	info.Hypervisor = info.Leaves.Leaf1ECX&(1<<31) != 0
+	if info.Hypervisor {
+		_, ebx, ecx, edx := id(cpuidHypervisorBase, 0)
+		info.HypervisorVendor = cpuidString(ebx, ecx, edx)
+	}
+
+	switch info.Vendor {
+	case VendorAMD, VendorHygon:
+		// TOPOEXT
+		if info.Leaves.Ext1ECX&(1<<22) != 0 {
+			info.Caches = cpuidDeterministicCaches(leaf, cpuidExtendedBase+0x1d)
+		} else {
+			info.Caches = cpuidAMDCaches(leaf)
+		}
+		info.TLBs = cpuidAMDTLBs(leaf)
+	default:
+		info.Caches = cpuidDeterministicCaches(leaf, 4)
+		info.TLBs = cpuidIntelTLBs(leaf, info.MaxLeaf)
+		info.Descriptors = cpuidDescriptors(leaf, info.MaxLeaf)
+	}
+
+	info.Features = cpuidFeatures(info.Leaves, xcr0)
+
+	return info
+}
+
+// Decodes the little endian registers holding vendor, brand or hypervisor
+// strings
+func cpuidString(regs ...uint32) string {
+	b := make([]byte, 0, len(regs)*4)
+
+	for _, r := range regs {
+		b = append(b, byte(r), byte(r>>8), byte(r>>16), byte(r>>24))
+	}
+
+	return strings.TrimSpace(strings.TrimRight(string(b), "\x00"))
+}
+
+// Decodes the processor signature of leaf 1
+func cpuidSignature(vendor string, eax uint32) (int, int, int) {
+	stepping := int(eax & 0xf)
+	model := int(eax>>4) & 0xf
+	family := int(eax>>8) & 0xf
+	extModel := int(eax>>16) & 0xf
+	extFamily := int(eax>>20) & 0xff
+
+	// Intel combines the extended model for families 6 and 15, AMD for
+	// family 15 only
+	if family == 0xf || (family == 6 && vendor != VendorAMD && vendor != VendorHygon) {
+		model += extModel << 4
+	}
+
+	if family == 0xf {
+		family += extFamily
+	}
+
+	return family, model, stepping
+}
+
+func cpuidCacheType(t uint32) string {
+	switch t {
+	case 1:
+		return CacheData
+	case 2:
+		return CacheInstruction
+	case 3:
+		return CacheUnified
+	}
+
+	return ""
+}
+
+// Reads a deterministic cache parameters leaf, 4 or 0x8000001d
+func cpuidDeterministicCaches(leaf cpuidFunc, l uint32) []CPUIDCache {
+	var caches []CPUIDCache
+
+	for sub := uint32(0); sub < 16; sub++ {
+		eax, ebx, ecx, _ := leaf(l, sub)
+
+		t := cpuidCacheType(eax & 0x1f)
+		if t == "" {
+			break
+		}
+
+		cc := CPUIDCache{
+			Level:         int(eax>>5) & 0x7,
+			Type:          t,
+			Ways:          int(ebx>>22) + 1,
+			LineSize:      int(ebx&0xfff) + 1,
+			Sets:          int(ecx) + 1,
+			SharedThreads: int(eax>>14)&0xfff + 1,
+		}
+
+		partitions := uint64(ebx>>12)&0x3ff + 1
+		cc.Size = uint64(cc.Ways) * partitions * uint64(cc.LineSize) * uint64(cc.Sets)
+
+		caches = append(caches, cc)
+	}
+
+	return caches
+}
+
+// Reads the legacy AMD cache leaves 0x80000005 and 0x80000006
+func cpuidAMDCaches(leaf cpuidFunc) []CPUIDCache {
+	var caches []CPUIDCache
+
+	_, _, ecx, edx := leaf(cpuidExtendedBase+5, 0)
+
+	l1 := func(reg uint32, t string) {
+		if reg == 0 {
+			return
+		}
+
+		ways := int(reg>>16) & 0xff
+		if ways == 0xff {
+			ways = -1
+		}
+
+		caches = append(caches, CPUIDCache{
+			Level:    1,
+			Type:     t,
+			Size:     uint64(reg>>24) * 1024,
+			Ways:     ways,
+			LineSize: int(reg & 0xff),
+		})
+	}
+
+	l1(ecx, CacheData)
+	l1(edx, CacheInstruction)
+
+	_, _, ecx, edx = leaf(cpuidExtendedBase+6, 0)
+
+	if ecx>>16 != 0 {
+		caches = append(caches, CPUIDCache{
+			Level:    2,
+			Type:     CacheUnified,
+			Size:     uint64(ecx>>16) * 1024,
+			Ways:     amdAssociativity[(ecx>>12)&0xf],
+			LineSize: int(ecx & 0xff),
+		})
+	}
+
+	if edx>>18 != 0 {
+		caches = append(caches, CPUIDCache{
+			Level:    3,
+			Type:     CacheUnified,
+			Size:     uint64(edx>>18) * 512 * 1024,
+			Ways:     amdAssociativity[(edx>>12)&0xf],
+			LineSize: int(edx & 0xff),
+		})
+	}
+
+	for i, cc := range caches {
+		if cc.Ways > 0 && cc.LineSize > 0 {
+			caches[i].Sets = int(cc.Size) / (cc.Ways * cc.LineSize)
+		}
+	}
+
+	return caches
+}
+
+// Reads the deterministic address translation parameters leaf 0x18
+func cpuidIntelTLBs(leaf cpuidFunc, maxLeaf uint32) []CPUIDTLB {
+	var tlbs []CPUIDTLB
+
+	if maxLeaf < 0x18 {
+		return tlbs
+	}
+
+	maxSub, _, _, _ := leaf(0x18, 0)
+
+	for sub := uint32(0); sub <= maxSub && sub < 64; sub++ {
+		_, ebx, ecx, edx := leaf(0x18, sub)
+
+		var t string
+		switch edx & 0x1f {
+		case 1, 4, 5:
+			// load only and store only TLBs are data TLBs
+			t = CacheData
+		case 2:
+			t = CacheInstruction
+		case 3:
+			t = CacheUnified
+		default:
+			continue
+		}
+
+		tlb := CPUIDTLB{
+			Level: int(edx>>5) & 0x7,
+			Type:  t,
+			Ways:  int(ebx >> 16),
+		}
+
+		for i, size := range []uint64{4 << 10, 2 << 20, 4 << 20, 1 << 30} {
+			if ebx&(1<<uint(i)) != 0 {
+				tlb.PageSizes = append(tlb.PageSizes, size)
+			}
+		}
+
+		tlb.Entries = tlb.Ways * int(ecx)
+
+		// fully associative
+		if edx&(1<<8) != 0 {
+			tlb.Ways = -1
+		}
+
+		tlbs = append(tlbs, tlb)
+	}
+
+	return tlbs
+}
+
+// Reads the AMD TLB leaves 0x80000005 and 0x80000006
+func cpuidAMDTLBs(leaf cpuidFunc) []CPUIDTLB {
+	var tlbs []CPUIDTLB
+
+	large := []uint64{2 << 20, 4 << 20}
+	small := []uint64{4 << 10}
+
+	l1 := func(reg uint32, pageSizes []uint64) {
+		for _, half := range []struct {
+			t string
+			v uint32
+		}{
+			{CacheData, reg >> 16},
+			{CacheInstruction, reg & 0xffff},
+		} {
+			entries := int(half.v & 0xff)
+			if entries == 0 {
+				continue
+			}
+
+			ways := int(half.v>>8) & 0xff
+			if ways == 0xff {
+				ways = -1
+			}
+
+			tlbs = append(tlbs, CPUIDTLB{Level: 1, Type: half.t, PageSizes: pageSizes, Entries: entries, Ways: ways})
+		}
+	}
+
+	l2 := func(reg uint32, pageSizes []uint64) {
+		for _, half := range []struct {
+			t string
+			v uint32
+		}{
+			{CacheData, reg >> 16},
+			{CacheInstruction, reg & 0xffff},
+		} {
+			entries := int(half.v & 0xfff)
+			if entries == 0 {
+				continue
+			}
+
+			ways := amdAssociativity[(half.v>>12)&0xf]
+
+			tlbs = append(tlbs, CPUIDTLB{Level: 2, Type: half.t, PageSizes: pageSizes, Entries: entries, Ways: ways})
+		}
+	}
+
+	eax, ebx, _, _ := leaf(cpuidExtendedBase+5, 0)
+	l1(ebx, small)
+	l1(eax, large)
+
+	eax, ebx, _, _ = leaf(cpuidExtendedBase+6, 0)
+	l2(ebx, small)
+	l2(eax, large)
+
+	return tlbs
+}
+
+// Returns the non null descriptors of leaf 2
+func cpuidDescriptors(leaf cpuidFunc, maxLeaf uint32) []uint8 {
+	var descriptors []uint8
+
+	if maxLeaf < 2 {
+		return descriptors
+	}
+
+	eax, ebx, ecx, edx := leaf(2, 0)
+
+	for i, reg := range []uint32{eax, ebx, ecx, edx} {
+		// registers with bit 31 set hold no descriptor
+		if reg&(1<<31) != 0 {
+			continue
+		}
+
+		for b := uint(0); b < 32; b += 8 {
+			d := uint8(reg >> b)
+
+			// the low byte of eax is always 1
+			if d == 0 || (i == 0 && b == 0) {
+				continue
+			}
+
+			descriptors = append(descriptors, d)
+		}
+	}
+
+	return descriptors
+}
+
+func cpuidFeatures(l CPUIDLeaves, xcr0 uint64) CPUFeatures {
+	var fs CPUFeatures
+
+	bits := []cpuidBits{
+		{l.Leaf1EDX, map[uint]CPUFeature{
+			0: FeatureFPU, 4: FeatureTSC, 8: FeatureCX8, 15: FeatureCMOV,
+			23: FeatureMMX, 24: FeatureFXSR, 25: FeatureSSE, 26: FeatureSSE2,
+			28: FeatureHT,
+		}},
+		{l.Leaf1ECX, map[uint]CPUFeature{
+			0: FeatureSSE3, 1: FeaturePCLMULQDQ, 5: FeatureVMX,
+			9: FeatureSSSE3, 13: FeatureCX16, 19: FeatureSSE41,
+			20: FeatureSSE42, 22: FeatureMOVBE, 23: FeaturePOPCNT,
+			25: FeatureAES, 26: FeatureXSAVE, 30: FeatureRDRAND,
+			31: FeatureHypervisor,
+		}},
+		{l.Leaf7EBX, map[uint]CPUFeature{
+			3: FeatureBMI1, 8: FeatureBMI2, 9: FeatureERMS, 18: FeatureRDSEED,
+			19: FeatureADX, 23: FeatureCLFLUSHOPT, 24: FeatureCLWB,
+			29: FeatureSHA,
+		}},
+		{l.Leaf7ECX, map[uint]CPUFeature{
+			8: FeatureGFNI,
+		}},
+		{l.Leaf7EDX, map[uint]CPUFeature{
+			4: FeatureFSRM,
+		}},
+		{l.Ext1EDX, map[uint]CPUFeature{
+			11: FeatureSYSCALL, 20: FeatureNX, 26: FeaturePDPE1GB,
+			27: FeatureRDTSCP, 29: FeatureLM,
+		}},
+		{l.Ext1ECX, map[uint]CPUFeature{
+			0: FeatureLAHFLM, 2: FeatureSVM, 5: FeatureLZCNT,
+		}},
+		{l.Ext7EDX, map[uint]CPUFeature{
+			8: FeatureInvariantTSC,
+		}},
+	}
+
+	osAVX := xcr0&(xcr0SSE|xcr0AVX) == xcr0SSE|xcr0AVX
+	osAVX512 := osAVX && xcr0&xcr0AVX512 == xcr0AVX512
+	osAMX := xcr0&xcr0AMX == xcr0AMX
+
+	if osAVX {
+		bits = append(bits, []cpuidBits{
+			{l.Leaf1ECX, map[uint]CPUFeature{12: FeatureFMA, 28: FeatureAVX, 29: FeatureF16C}},
+			{l.Leaf7EBX, map[uint]CPUFeature{5: FeatureAVX2}},
+			{l.Leaf7ECX, map[uint]CPUFeature{9: FeatureVAES, 10: FeatureVPCLMULQDQ}},
+			{l.Leaf71EAX, map[uint]CPUFeature{4: FeatureAVXVNNI}},
+		}...)
+	}
+
+	if osAVX512 {
+		bits = append(bits, []cpuidBits{
+			{l.Leaf7EBX, map[uint]CPUFeature{
+				16: FeatureAVX512F, 17: FeatureAVX512DQ, 28: FeatureAVX512CD,
+				30: FeatureAVX512BW, 31: FeatureAVX512VL,
+			}},
+			{l.Leaf7ECX, map[uint]CPUFeature{11: FeatureAVX512VNNI}},
+			{l.Leaf71EAX, map[uint]CPUFeature{5: FeatureAVX512BF16}},
+		}...)
+	}
+
+	if osAMX {
+		bits = append(bits, cpuidBits{l.Leaf7EDX, map[uint]CPUFeature{22: FeatureAMXBF16, 24: FeatureAMXTile, 25: FeatureAMXInt8}})
+	}
+
+	for _, b := range bits {
+		for bit, f := range b.features {
+			if b.reg&(1<<bit) != 0 {
+				fs.Set(f)
+			}
+		}
+	}
+
+	return fs
+}
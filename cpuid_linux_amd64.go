@@ -0,0 +1,11 @@
+package libsysinfo
+
+const (
+	cpuidSupported = true
+)
+
+// Implemented in cpuid_linux_amd64.s
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+// Reads the XCR0 register, only valid when the OSXSAVE bit is set
+func xgetbv() (eax, edx uint32)
@@ -0,0 +1,15 @@
+// +build linux,!amd64
+
+package libsysinfo
+
+const (
+	cpuidSupported = false
+)
+
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32) {
+	return 0, 0, 0, 0
+}
+
+func xgetbv() (eax, edx uint32) {
+	return 0, 0
+}
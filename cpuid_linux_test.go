@@ -0,0 +1,160 @@
+package libsysinfo
+
+import (
+	. "launchpad.net/gocheck"
+)
+
+type CPUIDTestSuite struct{}
+
+var (
+	_ = Suite(&CPUIDTestSuite{})
+)
+
+// Returns a cpuidFunc answering from regs, indexed by leaf and subleaf
+func fakeCPUID(regs map[[2]uint32][4]uint32) cpuidFunc {
+	return func(eaxArg, ecxArg uint32) (uint32, uint32, uint32, uint32) {
+		r := regs[[2]uint32{eaxArg, ecxArg}]
+		return r[0], r[1], r[2], r[3]
+	}
+}
+
+func (s *CPUIDTestSuite) TestCpuidSignature(c *C) {
+	signatures := []struct {
+		vendor   string
+		eax      uint32
+		expected [3]int
+	}{
+		// Core i7-8700
+		{VendorIntel, 0x000906ea, [3]int{6, 0x9e, 10}},
+		// Pentium 4
+		{VendorIntel, 0x00000f29, [3]int{15, 2, 9}},
+		// Ryzen 7 1700
+		{VendorAMD, 0x00800f11, [3]int{0x17, 1, 1}},
+		// EPYC 9654
+		{VendorAMD, 0x00a10f11, [3]int{0x19, 0x11, 1}},
+	}
+
+	for _, sig := range signatures {
+		family, model, stepping := cpuidSignature(sig.vendor, sig.eax)
+		c.Assert([3]int{family, model, stepping}, Equals, sig.expected)
+	}
+}
+
+func (s *CPUIDTestSuite) TestReadCPUID_Intel(c *C) {
+	id := fakeCPUID(map[[2]uint32][4]uint32{
+		// "GenuineIntel"
+		{0, 0}: {0x16, 0x756e6547, 0x6c65746e, 0x49656e69},
+		{1, 0}: {0x000906ea, 0, 0x9cd8220b | 1<<12 | 1<<28 | 1<<29 | 1<<31, 0xbfebfbff},
+		{2, 0}: {0x00feff01, 0, 0, 0x000000f0},
+		// L1d 48K 12 ways and L3 12M 16 ways shared by 16 threads
+		{4, 0}: {0x0 | 1 | 1<<5, 11<<22 | 63, 63, 0},
+		{4, 1}: {0x3 | 3<<5 | 15<<14, 15<<22 | 63, 12287, 0},
+		{7, 0}: {0, 1<<5 | 1<<16 | 1<<3 | 1<<8, 0, 0},
+
+		{0x80000000, 0}: {0x80000008, 0, 0, 0},
+		{0x80000001, 0}: {0, 0, 1<<0 | 1<<5, 1<<11 | 1<<20 | 1<<29},
+		// "Intel(R) Core(TM) i7-8700 CPU @ 3.20GHz"
+		{0x80000002, 0}: {0x65746e49, 0x2952286c, 0x726f4320, 0x4d542865},
+		{0x80000003, 0}: {0x37692029, 0x3037382d, 0x50432030, 0x20402055},
+		{0x80000004, 0}: {0x30322e33, 0x007a4847, 0, 0},
+		{0x80000007, 0}: {0, 0, 0, 1 << 8},
+
+		// "KVMKVMKVM"
+		{0x40000000, 0}: {0x40000001, 0x4b4d564b, 0x564b4d56, 0x0000004d},
+	})
+
+	// SSE and AVX state enabled, AVX-512 state disabled
+	obtained := readCPUID(id, 0x7)
+
+	c.Assert(obtained.Vendor, Equals, VendorIntel)
+	c.Assert(obtained.Brand, Equals, "Intel(R) Core(TM) i7-8700 CPU @ 3.20GHz")
+	c.Assert(obtained.MaxLeaf, Equals, uint32(0x16))
+	c.Assert(obtained.MaxExtendedLeaf, Equals, uint32(0x80000008))
+	c.Assert(obtained.Family, Equals, 6)
+	c.Assert(obtained.Model, Equals, 0x9e)
+	c.Assert(obtained.Stepping, Equals, 10)
+	c.Assert(obtained.Hypervisor, Equals, true)
+	c.Assert(obtained.HypervisorVendor, Equals, "KVMKVMKVM")
+	c.Assert(obtained.Descriptors, DeepEquals, []uint8{0xff, 0xfe, 0xf0})
+
+	c.Assert(obtained.Caches, DeepEquals, []CPUIDCache{
+		CPUIDCache{Level: 1, Type: CacheData, Size: 49152, Ways: 12, LineSize: 64, Sets: 64, SharedThreads: 1},
+		CPUIDCache{Level: 3, Type: CacheUnified, Size: 12582912, Ways: 16, LineSize: 64, Sets: 12288, SharedThreads: 16},
+	})
+
+	c.Assert(obtained.Features.HasAll(FeatureAVX, FeatureAVX2, FeatureFMA, FeatureBMI1, FeatureBMI2), Equals, true)
+	c.Assert(obtained.Features.HasAll(FeatureLM, FeatureSYSCALL, FeatureLAHFLM, FeatureLZCNT), Equals, true)
+	c.Assert(obtained.Features.Has(FeatureInvariantTSC), Equals, true)
+	c.Assert(obtained.Features.Has(FeatureHypervisor), Equals, true)
+
+	// not enabled by the OS
+	c.Assert(obtained.Features.Has(FeatureAVX512F), Equals, false)
+
+	c.Assert(obtained.Features.X86Level(), Equals, X86LevelV3)
+}
+
+func (s *CPUIDTestSuite) TestReadCPUID_NoOSSupport(c *C) {
+	id := fakeCPUID(map[[2]uint32][4]uint32{
+		{0, 0}: {0x1, 0x756e6547, 0x6c65746e, 0x49656e69},
+		{1, 0}: {0x000906ea, 0, 1 << 28, 0},
+	})
+
+	c.Assert(readCPUID(id, 0).Features.Has(FeatureAVX), Equals, false)
+	c.Assert(readCPUID(id, 0x7).Features.Has(FeatureAVX), Equals, true)
+}
+
+func (s *CPUIDTestSuite) TestReadCPUID_AMDLegacyLeaves(c *C) {
+	id := fakeCPUID(map[[2]uint32][4]uint32{
+		// "AuthenticAMD"
+		{0, 0}: {0x1, 0x68747541, 0x444d4163, 0x69746e65},
+		{1, 0}: {0x00100f42, 0, 0, 0},
+
+		{0x80000000, 0}: {0x80000006, 0, 0, 0},
+		// L1 TLBs: 4K 48 entries fully associative, 2M/4M 32/16 entries
+		// L1 caches: 64K 2 ways 64 bytes lines
+		{0x80000005, 0}: {0xff20ff10, 0xff30ff30, 0x40020140, 0x40020140},
+		// L2 TLB 4K 512 entries 4 ways, L2 512K 16 ways, L3 6M 48 ways
+		{0x80000006, 0}: {0, 0x42004200, 0x02008140, 0x0030b140},
+	})
+
+	obtained := readCPUID(id, 0)
+
+	c.Assert(obtained.Vendor, Equals, VendorAMD)
+	c.Assert(obtained.Family, Equals, 0x10)
+	c.Assert(obtained.Model, Equals, 4)
+	c.Assert(obtained.Hypervisor, Equals, false)
+	c.Assert(obtained.Brand, Equals, "")
+	c.Assert(obtained.Descriptors, IsNil)
+
+	c.Assert(obtained.Caches, DeepEquals, []CPUIDCache{
+		CPUIDCache{Level: 1, Type: CacheData, Size: 65536, Ways: 2, LineSize: 64, Sets: 512},
+		CPUIDCache{Level: 1, Type: CacheInstruction, Size: 65536, Ways: 2, LineSize: 64, Sets: 512},
+		CPUIDCache{Level: 2, Type: CacheUnified, Size: 524288, Ways: 16, LineSize: 64, Sets: 512},
+		CPUIDCache{Level: 3, Type: CacheUnified, Size: 6291456, Ways: 48, LineSize: 64, Sets: 2048},
+	})
+
+	small := []uint64{4096}
+	large := []uint64{2097152, 4194304}
+
+	c.Assert(obtained.TLBs, DeepEquals, []CPUIDTLB{
+		CPUIDTLB{Level: 1, Type: CacheData, PageSizes: small, Entries: 48, Ways: -1},
+		CPUIDTLB{Level: 1, Type: CacheInstruction, PageSizes: small, Entries: 48, Ways: -1},
+		CPUIDTLB{Level: 1, Type: CacheData, PageSizes: large, Entries: 32, Ways: -1},
+		CPUIDTLB{Level: 1, Type: CacheInstruction, PageSizes: large, Entries: 16, Ways: -1},
+		CPUIDTLB{Level: 2, Type: CacheData, PageSizes: small, Entries: 512, Ways: 4},
+		CPUIDTLB{Level: 2, Type: CacheInstruction, PageSizes: small, Entries: 512, Ways: 4},
+	})
+}
+
+func (s *CPUIDTestSuite) TestCPUID(c *C) {
+	if !cpuidSupported {
+		_, err := CPUID()
+		c.Assert(err, Equals, ErrCPUIDNotAvailable)
+		return
+	}
+
+	obtained, err := CPUID()
+	c.Assert(err, IsNil)
+	c.Assert(obtained.Vendor, Not(Equals), "")
+	c.Assert(obtained.Family, Not(Equals), 0)
+}
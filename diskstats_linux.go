@@ -0,0 +1,261 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// /proc/diskstats always counts 512 bytes sectors
+	diskStatsSectorSize = 512
+
+	// kernels < 4.18
+	diskStatsFields = 14
+
+	// kernels >= 4.18 add discard statistics
+	diskStatsFieldsDiscard = 18
+
+	// kernels >= 5.5 add flush statistics
+	diskStatsFieldsFlush = 20
+)
+
+// ----
+
+// I/O counters of a block device since boot. Times are in milliseconds.
+type DiskStat struct {
+	Major int
+	Minor int
+	Name  string
+
+	ReadsCompleted uint64
+	ReadsMerged    uint64
+	SectorsRead    uint64
+	ReadTime       uint64
+
+	WritesCompleted uint64
+	WritesMerged    uint64
+	SectorsWritten  uint64
+	WriteTime       uint64
+
+	InFlight     uint64
+	IOTime       uint64
+	WeightedTime uint64
+
+	// Zero on kernels older than 4.18
+	DiscardsCompleted uint64
+	DiscardsMerged    uint64
+	SectorsDiscarded  uint64
+	DiscardTime       uint64
+
+	// Zero on kernels older than 5.5
+	FlushesCompleted uint64
+	FlushTime        uint64
+}
+
+// Per device activity between two DiskStats() readings, as computed by
+// iostat -x
+type DiskRate struct {
+	Name string
+
+	ReadIOPS  float64
+	WriteIOPS float64
+
+	// In bytes per second
+	ReadThroughput  float64
+	WriteThroughput float64
+
+	// Average time in milliseconds for requests to be served, including the
+	// time spent in queue
+	ReadAwait  float64
+	WriteAwait float64
+	Await      float64
+
+	AvgQueueSize float64
+
+	// Percentage of the elapsed time during which the device was busy
+	Utilization float64
+}
+
+// Computes DiskRate values from successive DiskStats() readings. It is safe
+// for concurrent use.
+type DiskSampler struct {
+	mu       sync.Mutex
+	last     []DiskStat
+	lastTime time.Time
+}
+
+// ----
+
+func DiskStats() ([]DiskStat, error) {
+	buff, err := getDiskStats()
+	if err != nil {
+		return []DiskStat(nil), err
+	}
+
+	return processDiskStats(buff), nil
+}
+
+// Computes the activity of every device present in both readings, elapsed
+// being the time between them
+func DiskRates(before []DiskStat, after []DiskStat, elapsed time.Duration) []DiskRate {
+	var rates []DiskRate
+
+	if elapsed <= 0 {
+		return rates
+	}
+
+	previous := make(map[string]DiskStat, len(before))
+	for _, ds := range before {
+		previous[ds.Name] = ds
+	}
+
+	for _, cur := range after {
+		prev, exists := previous[cur.Name]
+		if !exists {
+			continue
+		}
+
+		rates = append(rates, diskRate(prev, cur, elapsed))
+	}
+
+	return rates
+}
+
+// Reads /proc/diskstats and returns the activity since the previous call.
+// The first call only records a reading and returns no rates.
+func (s *DiskSampler) Sample() ([]DiskRate, error) {
+	stats, err := DiskStats()
+	if err != nil {
+		return []DiskRate(nil), err
+	}
+
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rates []DiskRate
+	if s.last != nil {
+		rates = DiskRates(s.last, stats, now.Sub(s.lastTime))
+	}
+
+	s.last = stats
+	s.lastTime = now
+
+	return rates, nil
+}
+
+// ----
+
+// Returns the increase of a counter, assuming it was reset if it went
+// backwards (e.g. 32 bits counters wrapping on 32 bits kernels)
+func counterDelta(before uint64, after uint64) uint64 {
+	if after < before {
+		return after
+	}
+
+	return after - before
+}
+
+func diskRate(prev DiskStat, cur DiskStat, elapsed time.Duration) DiskRate {
+	secs := elapsed.Seconds()
+	msecs := secs * 1000
+
+	reads := float64(counterDelta(prev.ReadsCompleted, cur.ReadsCompleted))
+	writes := float64(counterDelta(prev.WritesCompleted, cur.WritesCompleted))
+	readTime := float64(counterDelta(prev.ReadTime, cur.ReadTime))
+	writeTime := float64(counterDelta(prev.WriteTime, cur.WriteTime))
+	sectorsRead := float64(counterDelta(prev.SectorsRead, cur.SectorsRead))
+	sectorsWritten := float64(counterDelta(prev.SectorsWritten, cur.SectorsWritten))
+	ioTime := float64(counterDelta(prev.IOTime, cur.IOTime))
+	weighted := float64(counterDelta(prev.WeightedTime, cur.WeightedTime))
+
+	r := DiskRate{
+		Name:            cur.Name,
+		ReadIOPS:        reads / secs,
+		WriteIOPS:       writes / secs,
+		ReadThroughput:  sectorsRead * diskStatsSectorSize / secs,
+		WriteThroughput: sectorsWritten * diskStatsSectorSize / secs,
+		AvgQueueSize:    weighted / msecs,
+		Utilization:     100 * ioTime / msecs,
+	}
+
+	if reads > 0 {
+		r.ReadAwait = readTime / reads
+	}
+
+	if writes > 0 {
+		r.WriteAwait = writeTime / writes
+	}
+
+	if reads+writes > 0 {
+		r.Await = (readTime + writeTime) / (reads + writes)
+	}
+
+	if r.Utilization > 100 {
+		r.Utilization = 100
+	}
+
+	return r
+}
+
+func processDiskStats(buff string) []DiskStat {
+	var stats []DiskStat
+
+	for _, line := range strings.Split(buff, "\n") {
+		fields := strings.Fields(line)
+
+		n := len(fields)
+		if n != diskStatsFields && n != diskStatsFieldsDiscard && n != diskStatsFieldsFlush {
+			continue
+		}
+
+		v := make([]uint64, n)
+		for i := 3; i < n; i++ {
+			v[i], _ = strconv.ParseUint(fields[i], 10, 64)
+		}
+
+		ds := DiskStat{
+			Major:           atoi(fields[0]),
+			Minor:           atoi(fields[1]),
+			Name:            fields[2],
+			ReadsCompleted:  v[3],
+			ReadsMerged:     v[4],
+			SectorsRead:     v[5],
+			ReadTime:        v[6],
+			WritesCompleted: v[7],
+			WritesMerged:    v[8],
+			SectorsWritten:  v[9],
+			WriteTime:       v[10],
+			InFlight:        v[11],
+			IOTime:          v[12],
+			WeightedTime:    v[13],
+		}
+
+		if n >= diskStatsFieldsDiscard {
+			ds.DiscardsCompleted = v[14]
+			ds.DiscardsMerged = v[15]
+			ds.SectorsDiscarded = v[16]
+			ds.DiscardTime = v[17]
+		}
+
+		if n >= diskStatsFieldsFlush {
+			ds.FlushesCompleted = v[18]
+			ds.FlushTime = v[19]
+		}
+
+		stats = append(stats, ds)
+	}
+
+	return stats
+}
+
+// ----
+
+func getDiskStats() (string, error) {
+	return readFile("/proc/diskstats")
+}
@@ -0,0 +1,95 @@
+package libsysinfo
+
+import (
+	. "launchpad.net/gocheck"
+	"time"
+)
+
+type DiskStatsTestSuite struct{}
+
+var (
+	_ = Suite(&DiskStatsTestSuite{})
+)
+
+func (s *DiskStatsTestSuite) TestProcessDiskStats(c *C) {
+	fixture := `   8       0 sda 4162 1073 255318 2628 3421 4471 124920 10196 0 4396 12820
+   8       1 sda1 3911 1073 251614 2556 3411 4471 124912 10188 0 4320 12740 0 0 0 0
+ 259       0 nvme0n1 179302 40223 12466990 32569 397106 295125 22050914 512352 2 193548 573684 11324 0 144416416 1408 30412 27353
+   7       0 loop0 0 0
+`
+	obtained := processDiskStats(fixture)
+
+	c.Assert(len(obtained), Equals, 3)
+
+	c.Assert(obtained[0], DeepEquals, DiskStat{
+		Major:           8,
+		Minor:           0,
+		Name:            "sda",
+		ReadsCompleted:  4162,
+		ReadsMerged:     1073,
+		SectorsRead:     255318,
+		ReadTime:        2628,
+		WritesCompleted: 3421,
+		WritesMerged:    4471,
+		SectorsWritten:  124920,
+		WriteTime:       10196,
+		InFlight:        0,
+		IOTime:          4396,
+		WeightedTime:    12820,
+	})
+
+	c.Assert(obtained[1].Name, Equals, "sda1")
+	c.Assert(obtained[1].DiscardsCompleted, Equals, uint64(0))
+
+	c.Assert(obtained[2].Name, Equals, "nvme0n1")
+	c.Assert(obtained[2].InFlight, Equals, uint64(2))
+	c.Assert(obtained[2].DiscardsCompleted, Equals, uint64(11324))
+	c.Assert(obtained[2].SectorsDiscarded, Equals, uint64(144416416))
+	c.Assert(obtained[2].DiscardTime, Equals, uint64(1408))
+	c.Assert(obtained[2].FlushesCompleted, Equals, uint64(30412))
+	c.Assert(obtained[2].FlushTime, Equals, uint64(27353))
+}
+
+func (s *DiskStatsTestSuite) TestDiskRates(c *C) {
+	before := []DiskStat{
+		DiskStat{Name: "sda", ReadsCompleted: 100, WritesCompleted: 200, SectorsRead: 1000, SectorsWritten: 2000, ReadTime: 100, WriteTime: 400, IOTime: 1000, WeightedTime: 500},
+		DiskStat{Name: "sdb"},
+	}
+
+	after := []DiskStat{
+		DiskStat{Name: "sda", ReadsCompleted: 300, WritesCompleted: 300, SectorsRead: 3000, SectorsWritten: 4000, ReadTime: 500, WriteTime: 600, IOTime: 1500, WeightedTime: 1500},
+		DiskStat{Name: "sdc"},
+	}
+
+	obtained := DiskRates(before, after, 2*time.Second)
+
+	c.Assert(len(obtained), Equals, 1)
+	c.Assert(obtained[0], DeepEquals, DiskRate{
+		Name:            "sda",
+		ReadIOPS:        100,
+		WriteIOPS:       50,
+		ReadThroughput:  512000,
+		WriteThroughput: 512000,
+		ReadAwait:       2,
+		WriteAwait:      2,
+		Await:           2,
+		AvgQueueSize:    0.5,
+		Utilization:     25,
+	})
+}
+
+func (s *DiskStatsTestSuite) TestDiskRates_Idle(c *C) {
+	stats := []DiskStat{DiskStat{Name: "sda", ReadsCompleted: 10}}
+
+	obtained := DiskRates(stats, stats, time.Second)
+
+	c.Assert(len(obtained), Equals, 1)
+	c.Assert(obtained[0], DeepEquals, DiskRate{Name: "sda"})
+
+	c.Assert(DiskRates(stats, stats, 0), IsNil)
+}
+
+func (s *DiskStatsTestSuite) TestCounterDelta(c *C) {
+	c.Assert(counterDelta(10, 15), Equals, uint64(5))
+	c.Assert(counterDelta(4294967290, 5), Equals, uint64(5))
+}
@@ -0,0 +1,217 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Subsystems owning a device-mapper device, decoded from its UUID prefix
+const (
+	DMTypeLVM       = "lvm"
+	DMTypeCrypt     = "crypt"
+	DMTypeMultipath = "multipath"
+)
+
+// ----
+
+type DMDevice struct {
+	// e.g. dm-0
+	Name string
+
+	// Name under /dev/mapper, e.g. vg0-root
+	MapperName string
+
+	// As set by the subsystem which created the device, e.g.
+	// LVM-<vg uuid><lv uuid>, CRYPT-LUKS2-<uuid>-<name> or mpath-<wwid>. May
+	// be empty.
+	UUID string
+
+	// One of the DMType* constants, empty for other subsystems
+	Type string
+
+	// The LUKS1, LUKS2, PLAIN... format of crypt devices, or the layer of
+	// internal LVM devices, e.g. tpool, real, cow. Empty for regular logical
+	// volumes.
+	Subtype string
+
+	// Number of the partition for partitions created by kpartx on top of
+	// another device-mapper device, e.g. a multipath one. 0 otherwise.
+	Partition int
+
+	// LVM volumes
+	VolumeGroup       string
+	LogicalVolume     string
+	VolumeGroupUUID   string
+	LogicalVolumeUUID string
+
+	// UUID of the LUKS header
+	LUKSUUID string
+
+	// World wide identifier of multipath devices
+	WWID string
+
+	// In bytes
+	Size uint64
+
+	ReadOnly  bool
+	Suspended bool
+
+	// Devices the device is mapped onto
+	Slaves []string
+
+	// Devices stacked on top of this one
+	Holders []string
+}
+
+// ----
+
+func DeviceMapper() ([]DMDevice, error) {
+	return readDMDevices(sysBlockPath), nil
+}
+
+// ----
+
+func readDMDevices(root string) []DMDevice {
+	var devices []DMDevice
+	var minors []int
+
+	paths, _ := filepath.Glob(filepath.Join(root, "dm-*"))
+	for _, path := range paths {
+		minor, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(path), "dm-"))
+		if err == nil {
+			minors = append(minors, minor)
+		}
+	}
+
+	// the glob sorts dm-10 before dm-2
+	sort.Ints(minors)
+
+	for _, minor := range minors {
+		devices = append(devices, readDMDevice(root, "dm-"+strconv.Itoa(minor)))
+	}
+
+	return devices
+}
+
+func readDMDevice(root string, name string) DMDevice {
+	dir := filepath.Join(root, name)
+
+	d := DMDevice{
+		Name:       name,
+		MapperName: sysfsString(filepath.Join(dir, "dm", "name")),
+		UUID:       sysfsString(filepath.Join(dir, "dm", "uuid")),
+		Size:       sysfsUint64(filepath.Join(dir, "size")) * sysfsSectorSize,
+		ReadOnly:   sysfsBool(filepath.Join(dir, "ro")),
+		Suspended:  sysfsBool(filepath.Join(dir, "dm", "suspended")),
+		Slaves:     dirNames(filepath.Join(dir, "slaves")),
+		Holders:    dirNames(filepath.Join(dir, "holders")),
+	}
+
+	return decodeDMUUID(d)
+}
+
+func decodeDMUUID(d DMDevice) DMDevice {
+	uuid := d.UUID
+
+	// kpartx prefixes the UUID of the parent device with partN-
+	if strings.HasPrefix(uuid, "part") {
+		i := strings.Index(uuid, "-")
+		if i > 0 {
+			n, err := strconv.Atoi(uuid[len("part"):i])
+			if err == nil {
+				d.Partition = n
+				uuid = uuid[i+1:]
+			}
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(uuid, "LVM-"):
+		d.Type = DMTypeLVM
+
+		// LVM-<32 chars vg uuid><32 chars lv uuid>[-layer]
+		ids := strings.TrimPrefix(uuid, "LVM-")
+		if len(ids) >= 64 {
+			d.VolumeGroupUUID = formatLVMUUID(ids[:32])
+			d.LogicalVolumeUUID = formatLVMUUID(ids[32:64])
+			d.Subtype = strings.TrimPrefix(ids[64:], "-")
+		}
+
+		if d.Partition == 0 {
+			d.VolumeGroup, d.LogicalVolume = splitLVMMapperName(d.MapperName)
+		}
+
+	case strings.HasPrefix(uuid, "CRYPT-"):
+		d.Type = DMTypeCrypt
+
+		// CRYPT-<format>-<32 hex digits uuid>-<name> for LUKS,
+		// CRYPT-<format>-<name> otherwise
+		parts := strings.SplitN(strings.TrimPrefix(uuid, "CRYPT-"), "-", 3)
+		d.Subtype = parts[0]
+
+		if strings.HasPrefix(d.Subtype, "LUKS") && len(parts) >= 2 {
+			d.LUKSUUID = formatUUID(parts[1])
+		}
+
+	case strings.HasPrefix(uuid, "mpath-"):
+		d.Type = DMTypeMultipath
+		d.WWID = strings.TrimPrefix(uuid, "mpath-")
+	}
+
+	return d
+}
+
+// Formats an LVM identifier the way LVM tools print them, e.g.
+// U1IGrH-UVjC-xRSw-PhMi-Ib2V-Ov5L-3efNyH
+func formatLVMUUID(id string) string {
+	groups := []int{6, 4, 4, 4, 4, 4, 6}
+
+	var parts []string
+	for _, n := range groups {
+		if len(id) < n {
+			return ""
+		}
+
+		parts = append(parts, id[:n])
+		id = id[n:]
+	}
+
+	return strings.Join(parts, "-")
+}
+
+// Formats 32 hexadecimal digits as 8-4-4-4-12, returns the input unchanged
+// if it is already formatted or is not a UUID
+func formatUUID(id string) string {
+	if len(id) != 32 {
+		return id
+	}
+
+	return strings.Join([]string{id[:8], id[8:12], id[12:16], id[16:20], id[20:]}, "-")
+}
+
+// Splits vg--data-root into vg-data and root, LVM doubling dashes in
+// volume names
+func splitLVMMapperName(name string) (string, string) {
+	for i := 0; i < len(name); i++ {
+		if name[i] != '-' {
+			continue
+		}
+
+		if i+1 < len(name) && name[i+1] == '-' {
+			i++
+			continue
+		}
+
+		unescape := func(s string) string {
+			return strings.Replace(s, "--", "-", -1)
+		}
+
+		return unescape(name[:i]), unescape(name[i+1:])
+	}
+
+	return "", ""
+}
@@ -0,0 +1,123 @@
+package libsysinfo
+
+import (
+	. "launchpad.net/gocheck"
+)
+
+type DMTestSuite struct{}
+
+var (
+	_ = Suite(&DMTestSuite{})
+)
+
+func (s *DMTestSuite) TestReadDMDevice(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"dm-0/dm/name":        "vg--data-lv--root\n",
+		"dm-0/dm/uuid":        "LVM-U1IGrHUVjCxRSwPhMiIb2VOv5L3efNyHk3QeNUx4LiT1xKkVjGz2fo9T3cXyqR8C\n",
+		"dm-0/dm/suspended":   "0\n",
+		"dm-0/size":           "41943040\n",
+		"dm-0/ro":             "0\n",
+		"dm-0/slaves/dm-1":    "",
+		"dm-1/dm/name":        "luks-0f8e4b55-9d2c-4a6b-a4c5-2d8e2b1f3c7a\n",
+		"dm-1/dm/uuid":        "CRYPT-LUKS2-0f8e4b559d2c4a6ba4c52d8e2b1f3c7a-luks-0f8e4b55-9d2c-4a6b-a4c5-2d8e2b1f3c7a\n",
+		"dm-1/dm/suspended":   "1\n",
+		"dm-1/slaves/sda2":    "",
+		"dm-1/holders/dm-0":   "",
+		"dm-2/dm/name":        "mpatha\n",
+		"dm-2/dm/uuid":        "mpath-3600508b4000156d700012000000b0000\n",
+		"dm-2/ro":             "1\n",
+		"dm-2/slaves/sdb":     "",
+		"dm-2/slaves/sdc":     "",
+		"dm-3/dm/name":        "mpatha1\n",
+		"dm-3/dm/uuid":        "part1-mpath-3600508b4000156d700012000000b0000\n",
+		"dm-4/dm/name":        "vg0-pool_tdata\n",
+		"dm-4/dm/uuid":        "LVM-U1IGrHUVjCxRSwPhMiIb2VOv5L3efNyHk3QeNUx4LiT1xKkVjGz2fo9T3cXyqR8C-tdata\n",
+		"dm-5/dm/name":        "scratch\n",
+		"dm-5/dm/uuid":        "\n",
+		"dm-6/dm/name":        "swap_crypt\n",
+		"dm-6/dm/uuid":        "CRYPT-PLAIN-swap_crypt\n",
+		"dm-6/dm/suspended":   "0\n",
+		"dm-6/slaves/nvme0n1": "",
+	})
+
+	d := readDMDevice(root, "dm-0")
+	c.Check(d, DeepEquals, DMDevice{
+		Name:              "dm-0",
+		MapperName:        "vg--data-lv--root",
+		UUID:              "LVM-U1IGrHUVjCxRSwPhMiIb2VOv5L3efNyHk3QeNUx4LiT1xKkVjGz2fo9T3cXyqR8C",
+		Type:              DMTypeLVM,
+		VolumeGroup:       "vg-data",
+		LogicalVolume:     "lv-root",
+		VolumeGroupUUID:   "U1IGrH-UVjC-xRSw-PhMi-Ib2V-Ov5L-3efNyH",
+		LogicalVolumeUUID: "k3QeNU-x4Li-T1xK-kVjG-z2fo-9T3c-XyqR8C",
+		Size:              41943040 * 512,
+		Slaves:            []string{"dm-1"},
+	})
+
+	d = readDMDevice(root, "dm-1")
+	c.Check(d.Type, Equals, DMTypeCrypt)
+	c.Check(d.Subtype, Equals, "LUKS2")
+	c.Check(d.LUKSUUID, Equals, "0f8e4b55-9d2c-4a6b-a4c5-2d8e2b1f3c7a")
+	c.Check(d.Suspended, Equals, true)
+	c.Check(d.Slaves, DeepEquals, []string{"sda2"})
+	c.Check(d.Holders, DeepEquals, []string{"dm-0"})
+
+	d = readDMDevice(root, "dm-2")
+	c.Check(d.Type, Equals, DMTypeMultipath)
+	c.Check(d.WWID, Equals, "3600508b4000156d700012000000b0000")
+	c.Check(d.ReadOnly, Equals, true)
+	c.Check(d.Slaves, DeepEquals, []string{"sdb", "sdc"})
+
+	d = readDMDevice(root, "dm-3")
+	c.Check(d.Type, Equals, DMTypeMultipath)
+	c.Check(d.Partition, Equals, 1)
+	c.Check(d.WWID, Equals, "3600508b4000156d700012000000b0000")
+
+	d = readDMDevice(root, "dm-4")
+	c.Check(d.Type, Equals, DMTypeLVM)
+	c.Check(d.Subtype, Equals, "tdata")
+	c.Check(d.VolumeGroup, Equals, "vg0")
+	c.Check(d.LogicalVolume, Equals, "pool_tdata")
+
+	d = readDMDevice(root, "dm-5")
+	c.Check(d.Type, Equals, "")
+	c.Check(d.UUID, Equals, "")
+
+	d = readDMDevice(root, "dm-6")
+	c.Check(d.Type, Equals, DMTypeCrypt)
+	c.Check(d.Subtype, Equals, "PLAIN")
+	c.Check(d.LUKSUUID, Equals, "")
+}
+
+func (s *DMTestSuite) TestReadDMDevices(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"dm-0/dm/name":  "root\n",
+		"dm-2/dm/name":  "home\n",
+		"dm-10/dm/name": "swap\n",
+	})
+
+	var names []string
+	for _, d := range readDMDevices(root) {
+		names = append(names, d.Name)
+	}
+
+	c.Assert(names, DeepEquals, []string{"dm-0", "dm-2", "dm-10"})
+}
+
+func (s *DMTestSuite) TestSplitLVMMapperName(c *C) {
+	vg, lv := splitLVMMapperName("vg0-root")
+	c.Check(vg, Equals, "vg0")
+	c.Check(lv, Equals, "root")
+
+	vg, lv = splitLVMMapperName("my--vg-my--lv")
+	c.Check(vg, Equals, "my-vg")
+	c.Check(lv, Equals, "my-lv")
+
+	vg, lv = splitLVMMapperName("nodash")
+	c.Check(vg, Equals, "")
+	c.Check(lv, Equals, "")
+}
@@ -0,0 +1,195 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"strings"
+)
+
+// ----
+
+type HostsEntry struct {
+	IP string
+
+	// Canonical name first, then aliases. Duplicates are dropped
+	Names []string
+}
+
+type NSSwitchSource struct {
+	Name string
+
+	// Action items written in brackets after the source
+	Actions []NSSwitchAction
+}
+
+type NSSwitchAction struct {
+	// Set when the status is prefixed with "!"
+	Negated bool
+
+	// success, notfound, unavail or tryagain
+	Status string
+
+	// return, continue or merge
+	Action string
+}
+
+// ----
+
+// Returns the /etc/hosts entries in file order. Lines sharing the same IP are
+// merged into a single entry.
+func Hosts() ([]HostsEntry, error) {
+	buff, err := getHosts()
+	if err != nil {
+		return []HostsEntry(nil), err
+	}
+
+	return processHosts(buff), nil
+}
+
+// Returns the sources configured for each database of /etc/nsswitch.conf,
+// in lookup order
+func NSSwitch() (map[string][]NSSwitchSource, error) {
+	buff, err := getNSSwitch()
+	if err != nil {
+		return map[string][]NSSwitchSource(nil), err
+	}
+
+	return processNSSwitch(buff), nil
+}
+
+// ----
+
+func stripComment(line string) string {
+	pos := strings.Index(line, "#")
+	if pos > -1 {
+		line = line[:pos]
+	}
+
+	return strings.TrimSpace(line)
+}
+
+func processHosts(buff string) []HostsEntry {
+	var entries []HostsEntry
+	positions := make(map[string]int)
+
+	for _, line := range strings.Split(buff, "\n") {
+		fields := strings.Fields(stripComment(line))
+		if len(fields) < 2 {
+			continue
+		}
+
+		ip := fields[0]
+
+		pos, exists := positions[ip]
+		if !exists {
+			pos = len(entries)
+			positions[ip] = pos
+			entries = append(entries, HostsEntry{IP: ip})
+		}
+
+		for _, name := range fields[1:] {
+			if !containsString(entries[pos].Names, name) {
+				entries[pos].Names = append(entries[pos].Names, name)
+			}
+		}
+	}
+
+	return entries
+}
+
+func processNSSwitch(buff string) map[string][]NSSwitchSource {
+	databases := make(map[string][]NSSwitchSource)
+
+	for _, line := range strings.Split(buff, "\n") {
+		line = stripComment(line)
+
+		pos := strings.Index(line, ":")
+		if pos == -1 {
+			continue
+		}
+
+		db := strings.TrimSpace(line[:pos])
+		if db == "" {
+			continue
+		}
+
+		databases[db] = processNSSwitchSources(line[pos+1:])
+	}
+
+	return databases
+}
+
+func processNSSwitchSources(spec string) []NSSwitchSource {
+	var sources []NSSwitchSource
+
+	for len(spec) > 0 {
+		spec = strings.TrimSpace(spec)
+		if len(spec) <= 0 {
+			break
+		}
+
+		if spec[0] != '[' {
+			end := strings.IndexAny(spec, " \t[")
+			if end == -1 {
+				end = len(spec)
+			}
+
+			sources = append(sources, NSSwitchSource{Name: spec[:end]})
+			spec = spec[end:]
+			continue
+		}
+
+		end := strings.Index(spec, "]")
+		if end == -1 {
+			end = len(spec)
+		}
+
+		actions := processNSSwitchActions(spec[1:end])
+		if len(sources) > 0 {
+			last := len(sources) - 1
+			sources[last].Actions = append(sources[last].Actions, actions...)
+		}
+
+		if end == len(spec) {
+			break
+		}
+		spec = spec[end+1:]
+	}
+
+	return sources
+}
+
+func processNSSwitchActions(items string) []NSSwitchAction {
+	var actions []NSSwitchAction
+
+	for _, item := range strings.Fields(items) {
+		parts := strings.Split(item, "=")
+		if len(parts) != 2 {
+			continue
+		}
+
+		a := NSSwitchAction{
+			Status: strings.ToLower(strings.TrimSpace(parts[0])),
+			Action: strings.ToLower(strings.TrimSpace(parts[1])),
+		}
+
+		if strings.HasPrefix(a.Status, "!") {
+			a.Negated = true
+			a.Status = a.Status[1:]
+		}
+
+		actions = append(actions, a)
+	}
+
+	return actions
+}
+
+// ----
+
+func getHosts() (string, error) {
+	return readFile("/etc/hosts")
+}
+
+func getNSSwitch() (string, error) {
+	return readFile("/etc/nsswitch.conf")
+}
@@ -0,0 +1,80 @@
+package libsysinfo
+
+import (
+	. "launchpad.net/gocheck"
+)
+
+type HostsTestSuite struct{}
+
+var (
+	_ = Suite(&HostsTestSuite{})
+)
+
+func (s *HostsTestSuite) TestProcessHosts(c *C) {
+	fixture := `# The following lines are desirable for IPv4 capable hosts
+127.0.0.1	localhost
+127.0.1.1	wheezy64-puppet3.vagrantup.com	wheezy64-puppet3 # added by vagrant
+127.0.0.1	localhost.localdomain localhost
+
+# The following lines are desirable for IPv6 capable hosts
+::1     ip6-localhost ip6-loopback
+10.0.2.2
+`
+	obtained := processHosts(fixture)
+
+	expected := []HostsEntry{
+		HostsEntry{
+			IP:    "127.0.0.1",
+			Names: []string{"localhost", "localhost.localdomain"},
+		},
+		HostsEntry{
+			IP:    "127.0.1.1",
+			Names: []string{"wheezy64-puppet3.vagrantup.com", "wheezy64-puppet3"},
+		},
+		HostsEntry{
+			IP:    "::1",
+			Names: []string{"ip6-localhost", "ip6-loopback"},
+		},
+	}
+
+	c.Assert(obtained, DeepEquals, expected)
+}
+
+func (s *HostsTestSuite) TestProcessNSSwitch(c *C) {
+	fixture := `# /etc/nsswitch.conf
+passwd:         compat
+group:          files systemd
+
+hosts:          files mdns4_minimal [NOTFOUND=return] dns [!UNAVAIL=return tryagain=continue]myhostname
+networks:       files # local only
+`
+	obtained := processNSSwitch(fixture)
+
+	c.Assert(len(obtained), Equals, 4)
+	c.Assert(obtained["passwd"], DeepEquals, []NSSwitchSource{
+		NSSwitchSource{Name: "compat"},
+	})
+	c.Assert(obtained["networks"], DeepEquals, []NSSwitchSource{
+		NSSwitchSource{Name: "files"},
+	})
+
+	expected := []NSSwitchSource{
+		NSSwitchSource{Name: "files"},
+		NSSwitchSource{
+			Name: "mdns4_minimal",
+			Actions: []NSSwitchAction{
+				NSSwitchAction{Status: "notfound", Action: "return"},
+			},
+		},
+		NSSwitchSource{
+			Name: "dns",
+			Actions: []NSSwitchAction{
+				NSSwitchAction{Negated: true, Status: "unavail", Action: "return"},
+				NSSwitchAction{Status: "tryagain", Action: "continue"},
+			},
+		},
+		NSSwitchSource{Name: "myhostname"},
+	}
+
+	c.Assert(obtained["hosts"], DeepEquals, expected)
+}
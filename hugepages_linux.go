@@ -0,0 +1,133 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	sysHugePagesPath = "/sys/kernel/mm/hugepages"
+	sysTHPPath       = "/sys/kernel/mm/transparent_hugepage"
+)
+
+// ----
+
+type HugePagesInfo struct {
+	// Pools of every supported huge page size
+	Pools []HugePagePool
+
+	// Pools per NUMA node id, empty on kernels without NUMA support
+	NodePools map[int][]HugePagePool
+
+	THP TransparentHugePages
+}
+
+// Transparent huge pages settings. Settings are empty when the kernel is
+// built without CONFIG_TRANSPARENT_HUGEPAGE.
+type TransparentHugePages struct {
+	// always, madvise or never
+	Enabled string
+
+	// always, defer, defer+madvise, madvise or never
+	Defrag string
+
+	// always, within_size, advise, never, deny or force
+	ShmemEnabled string
+
+	UseZeroPage bool
+
+	// In bytes
+	PMDSize uint64
+
+	// Per size settings of multi-size THP (kernels >= 6.8), indexed by page
+	// size in bytes. "inherit" means the global Enabled value applies.
+	SizeEnabled map[uint64]string
+
+	Khugepaged Khugepaged
+}
+
+type Khugepaged struct {
+	Defrag              bool
+	PagesToScan         int
+	ScanSleepMillisecs  int
+	AllocSleepMillisecs int
+	MaxPtesNone         int
+	MaxPtesSwap         int
+	MaxPtesShared       int
+
+	PagesCollapsed uint64
+	FullScans      uint64
+}
+
+// ----
+
+func HugePages() (HugePagesInfo, error) {
+	return readHugePages(sysHugePagesPath, sysTHPPath, sysNodePath)
+}
+
+// ----
+
+func readHugePages(hugePagesDir string, thpDir string, nodeDir string) (HugePagesInfo, error) {
+	hp := HugePagesInfo{
+		NodePools: make(map[int][]HugePagePool),
+		THP:       readTHP(thpDir),
+	}
+
+	// kernels built without hugetlbfs still support transparent hugepages
+	_, err := os.Stat(hugePagesDir)
+	if os.IsNotExist(err) {
+		return hp, nil
+	}
+	if err != nil {
+		return hp, err
+	}
+
+	hp.Pools = readHugePagePools(hugePagesDir)
+
+	for _, n := range readNUMANodes(nodeDir) {
+		hp.NodePools[n.ID] = n.HugePages
+	}
+
+	return hp, nil
+}
+
+func readTHP(dir string) TransparentHugePages {
+	khugepaged := filepath.Join(dir, "khugepaged")
+
+	thp := TransparentHugePages{
+		Enabled:      bracketedValue(sysfsString(filepath.Join(dir, "enabled"))),
+		Defrag:       bracketedValue(sysfsString(filepath.Join(dir, "defrag"))),
+		ShmemEnabled: bracketedValue(sysfsString(filepath.Join(dir, "shmem_enabled"))),
+		UseZeroPage:  sysfsBool(filepath.Join(dir, "use_zero_page")),
+		PMDSize:      sysfsUint64(filepath.Join(dir, "hpage_pmd_size")),
+		Khugepaged: Khugepaged{
+			Defrag:              sysfsBool(filepath.Join(khugepaged, "defrag")),
+			PagesToScan:         sysfsInt(filepath.Join(khugepaged, "pages_to_scan")),
+			ScanSleepMillisecs:  sysfsInt(filepath.Join(khugepaged, "scan_sleep_millisecs")),
+			AllocSleepMillisecs: sysfsInt(filepath.Join(khugepaged, "alloc_sleep_millisecs")),
+			MaxPtesNone:         sysfsInt(filepath.Join(khugepaged, "max_ptes_none")),
+			MaxPtesSwap:         sysfsInt(filepath.Join(khugepaged, "max_ptes_swap")),
+			MaxPtesShared:       sysfsInt(filepath.Join(khugepaged, "max_ptes_shared")),
+			PagesCollapsed:      sysfsUint64(filepath.Join(khugepaged, "pages_collapsed")),
+			FullScans:           sysfsUint64(filepath.Join(khugepaged, "full_scans")),
+		},
+	}
+
+	for _, name := range dirNames(dir) {
+		size, ok := parseHugePagesDirName(name)
+		if !ok {
+			continue
+		}
+
+		if thp.SizeEnabled == nil {
+			thp.SizeEnabled = make(map[uint64]string)
+		}
+
+		enabled := sysfsString(filepath.Join(dir, name, "enabled"))
+		thp.SizeEnabled[size] = bracketedValue(enabled)
+	}
+
+	return thp
+}
@@ -0,0 +1,120 @@
+package libsysinfo
+
+import (
+	"path/filepath"
+
+	. "launchpad.net/gocheck"
+)
+
+type HugePagesTestSuite struct{}
+
+var (
+	_ = Suite(&HugePagesTestSuite{})
+)
+
+func (s *HugePagesTestSuite) TestReadHugePagePools(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"hugepages-2048kB/nr_hugepages":               "1024\n",
+		"hugepages-2048kB/free_hugepages":             "1000\n",
+		"hugepages-2048kB/resv_hugepages":             "16\n",
+		"hugepages-2048kB/surplus_hugepages":          "2\n",
+		"hugepages-2048kB/nr_overcommit_hugepages":    "64\n",
+		"hugepages-1048576kB/nr_hugepages":            "4\n",
+		"hugepages-1048576kB/free_hugepages":          "4\n",
+		"hugepages-1048576kB/resv_hugepages":          "0\n",
+		"hugepages-1048576kB/surplus_hugepages":       "0\n",
+		"hugepages-1048576kB/nr_overcommit_hugepages": "0\n",
+		"unrelated/nr_hugepages":                      "1\n",
+	})
+
+	obtained := readHugePagePools(root)
+
+	expected := []HugePagePool{
+		HugePagePool{PageSize: 1073741824, Total: 4, Free: 4},
+		HugePagePool{PageSize: 2097152, Total: 1024, Free: 1000, Reserved: 16, Surplus: 2, Overcommit: 64},
+	}
+
+	c.Assert(obtained, DeepEquals, expected)
+}
+
+func (s *HugePagesTestSuite) TestReadHugePages(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"hugepages/hugepages-2048kB/nr_hugepages":   "8\n",
+		"hugepages/hugepages-2048kB/free_hugepages": "8\n",
+		"node/online": "0\n",
+		"node/node0/hugepages/hugepages-2048kB/nr_hugepages":   "8\n",
+		"node/node0/hugepages/hugepages-2048kB/free_hugepages": "8\n",
+		"transparent_hugepage/enabled":                         "always [madvise] never\n",
+	})
+
+	obtained, err := readHugePages(filepath.Join(root, "hugepages"), filepath.Join(root, "transparent_hugepage"), filepath.Join(root, "node"))
+	c.Assert(err, IsNil)
+	c.Assert(obtained.Pools, DeepEquals, []HugePagePool{{PageSize: 2097152, Total: 8, Free: 8}})
+	c.Assert(obtained.NodePools[0], DeepEquals, obtained.Pools)
+	c.Assert(obtained.THP.Enabled, Equals, "madvise")
+
+	// no hugetlbfs
+	obtained, err = readHugePages(filepath.Join(root, "missing"), filepath.Join(root, "transparent_hugepage"), filepath.Join(root, "node"))
+	c.Assert(err, IsNil)
+	c.Assert(obtained.Pools, IsNil)
+	c.Assert(obtained.NodePools, HasLen, 0)
+	c.Assert(obtained.THP.Enabled, Equals, "madvise")
+}
+
+func (s *HugePagesTestSuite) TestReadTHP(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"enabled":                          "always [madvise] never\n",
+		"defrag":                           "always defer defer+madvise [madvise] never\n",
+		"shmem_enabled":                    "always within_size advise [never] deny force\n",
+		"use_zero_page":                    "1\n",
+		"hpage_pmd_size":                   "2097152\n",
+		"hugepages-64kB/enabled":           "always [inherit] madvise never\n",
+		"hugepages-2048kB/enabled":         "always inherit madvise [never]\n",
+		"khugepaged/defrag":                "1\n",
+		"khugepaged/pages_to_scan":         "4096\n",
+		"khugepaged/scan_sleep_millisecs":  "10000\n",
+		"khugepaged/alloc_sleep_millisecs": "60000\n",
+		"khugepaged/max_ptes_none":         "511\n",
+		"khugepaged/max_ptes_swap":         "64\n",
+		"khugepaged/max_ptes_shared":       "256\n",
+		"khugepaged/pages_collapsed":       "12\n",
+		"khugepaged/full_scans":            "3\n",
+	})
+
+	obtained := readTHP(root)
+
+	expected := TransparentHugePages{
+		Enabled:      "madvise",
+		Defrag:       "madvise",
+		ShmemEnabled: "never",
+		UseZeroPage:  true,
+		PMDSize:      2097152,
+		SizeEnabled: map[uint64]string{
+			65536:   "inherit",
+			2097152: "never",
+		},
+		Khugepaged: Khugepaged{
+			Defrag:              true,
+			PagesToScan:         4096,
+			ScanSleepMillisecs:  10000,
+			AllocSleepMillisecs: 60000,
+			MaxPtesNone:         511,
+			MaxPtesSwap:         64,
+			MaxPtesShared:       256,
+			PagesCollapsed:      12,
+			FullScans:           3,
+		},
+	}
+
+	c.Assert(obtained, DeepEquals, expected)
+}
+
+func (s *HugePagesTestSuite) TestReadTHP_Unsupported(c *C) {
+	c.Assert(readTHP(c.MkDir()), DeepEquals, TransparentHugePages{})
+}
@@ -0,0 +1,321 @@
+package libsysinfo
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A hardware identifiers database in the pci.ids / usb.ids format, see
+// https://pci-ids.ucw.cz and http://www.linux-usb.org/usb-ids.html
+//
+// Lookups return an empty string for unknown identifiers.
+type IDDatabase struct {
+	vendors map[uint16]*idVendor
+	classes map[uint8]*idClass
+}
+
+type idVendor struct {
+	name    string
+	devices map[uint16]*idDevice
+}
+
+type idDevice struct {
+	name string
+
+	// PCI subsystems indexed by subvendor<<16 | subdevice, USB interfaces
+	// indexed by interface number
+	subsystems map[uint32]string
+}
+
+type idClass struct {
+	name       string
+	subclasses map[uint8]*idSubclass
+}
+
+type idSubclass struct {
+	name    string
+	progIFs map[uint8]string
+}
+
+// Parsed databases indexed by path. Failed loads are not kept, so that they
+// are retried on the next lookup
+type idDatabases struct {
+	set map[string]*IDDatabase
+	mu  sync.RWMutex
+}
+
+func newIDDatabases() *idDatabases {
+	return &idDatabases{
+		set: make(map[string]*IDDatabase),
+	}
+}
+
+// Returns the database of the first existing path, or the one parsed from
+// fallback when none exists
+func (s *idDatabases) load(paths []string, fallback string) (*IDDatabase, error) {
+	for _, path := range paths {
+		db, present := s.get(path)
+		if present {
+			return db, nil
+		}
+
+		db, err := LoadIDDatabase(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		s.put(path, db)
+
+		return db, nil
+	}
+
+	db, present := s.get("")
+	if present {
+		return db, nil
+	}
+
+	db, err := ParseIDDatabase(strings.NewReader(fallback))
+	if err != nil {
+		return nil, err
+	}
+
+	s.put("", db)
+
+	return db, nil
+}
+
+func (s *idDatabases) get(path string) (*IDDatabase, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	db, present := s.set[path]
+	return db, present
+}
+
+func (s *idDatabases) put(path string, db *IDDatabase) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set[path] = db
+}
+
+// ----
+
+func LoadIDDatabase(path string) (*IDDatabase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseIDDatabase(f)
+}
+
+func ParseIDDatabase(r io.Reader) (*IDDatabase, error) {
+	db := &IDDatabase{
+		vendors: make(map[uint16]*idVendor),
+		classes: make(map[uint8]*idClass),
+	}
+
+	var vendor *idVendor
+	var device *idDevice
+	var class *idClass
+	var subclass *idSubclass
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		depth := len(line) - len(strings.TrimLeft(line, "\t"))
+		id, name := splitIDLine(line[depth:])
+
+		switch {
+		case depth == 0 && strings.HasPrefix(line, "C "):
+			vendor, device, subclass = nil, nil, nil
+
+			cid, cname := splitIDLine(line[2:])
+			v, err := strconv.ParseUint(cid, 16, 8)
+			if err != nil {
+				class = nil
+				continue
+			}
+
+			class = &idClass{name: cname, subclasses: make(map[uint8]*idSubclass)}
+			db.classes[uint8(v)] = class
+
+		case depth == 0:
+			class, subclass, device = nil, nil, nil
+
+			// other sections of usb.ids (HID usages, languages...) are
+			// not vendors
+			v, err := strconv.ParseUint(id, 16, 16)
+			if len(id) != 4 || err != nil {
+				vendor = nil
+				continue
+			}
+
+			vendor = &idVendor{name: name, devices: make(map[uint16]*idDevice)}
+			db.vendors[uint16(v)] = vendor
+
+		case depth == 1 && vendor != nil:
+			v, err := strconv.ParseUint(id, 16, 16)
+			if err != nil {
+				device = nil
+				continue
+			}
+
+			device = &idDevice{name: name, subsystems: make(map[uint32]string)}
+			vendor.devices[uint16(v)] = device
+
+		case depth == 2 && device != nil:
+			// "subvendor subdevice  name" in pci.ids, "interface  name" in
+			// usb.ids
+			ids := strings.Fields(id)
+			if len(ids) <= 0 {
+				continue
+			}
+
+			sv, err := strconv.ParseUint(ids[0], 16, 16)
+			if err != nil {
+				continue
+			}
+
+			if len(ids) == 1 {
+				device.subsystems[uint32(sv)] = name
+				continue
+			}
+
+			sd, err := strconv.ParseUint(ids[1], 16, 16)
+			if err != nil {
+				continue
+			}
+
+			device.subsystems[uint32(sv)<<16|uint32(sd)] = name
+
+		case depth == 1 && class != nil:
+			v, err := strconv.ParseUint(id, 16, 8)
+			if err != nil {
+				subclass = nil
+				continue
+			}
+
+			subclass = &idSubclass{name: name, progIFs: make(map[uint8]string)}
+			class.subclasses[uint8(v)] = subclass
+
+		case depth == 2 && subclass != nil:
+			v, err := strconv.ParseUint(id, 16, 8)
+			if err != nil {
+				continue
+			}
+
+			subclass.progIFs[uint8(v)] = name
+		}
+	}
+
+	return db, scanner.Err()
+}
+
+func (db *IDDatabase) VendorName(vendor uint16) string {
+	v, exists := db.vendors[vendor]
+	if !exists {
+		return ""
+	}
+
+	return v.name
+}
+
+func (db *IDDatabase) DeviceName(vendor uint16, device uint16) string {
+	d := db.device(vendor, device)
+	if d == nil {
+		return ""
+	}
+
+	return d.name
+}
+
+// Returns the name of a PCI subsystem
+func (db *IDDatabase) SubsystemName(vendor uint16, device uint16, subvendor uint16, subdevice uint16) string {
+	d := db.device(vendor, device)
+	if d == nil {
+		return ""
+	}
+
+	return d.subsystems[uint32(subvendor)<<16|uint32(subdevice)]
+}
+
+// Returns the name of a USB interface
+func (db *IDDatabase) InterfaceName(vendor uint16, device uint16, iface uint8) string {
+	d := db.device(vendor, device)
+	if d == nil {
+		return ""
+	}
+
+	return d.subsystems[uint32(iface)]
+}
+
+func (db *IDDatabase) ClassName(class uint8) string {
+	c, exists := db.classes[class]
+	if !exists {
+		return ""
+	}
+
+	return c.name
+}
+
+func (db *IDDatabase) SubclassName(class uint8, subclass uint8) string {
+	s := db.subclass(class, subclass)
+	if s == nil {
+		return ""
+	}
+
+	return s.name
+}
+
+// Returns the name of a PCI programming interface or of a USB protocol
+func (db *IDDatabase) ProgIFName(class uint8, subclass uint8, progIF uint8) string {
+	s := db.subclass(class, subclass)
+	if s == nil {
+		return ""
+	}
+
+	return s.progIFs[progIF]
+}
+
+// ----
+
+func (db *IDDatabase) device(vendor uint16, device uint16) *idDevice {
+	v, exists := db.vendors[vendor]
+	if !exists {
+		return nil
+	}
+
+	return v.devices[device]
+}
+
+func (db *IDDatabase) subclass(class uint8, subclass uint8) *idSubclass {
+	c, exists := db.classes[class]
+	if !exists {
+		return nil
+	}
+
+	return c.subclasses[subclass]
+}
+
+// Splits "8086  Intel Corporation" into its identifier and name
+func splitIDLine(s string) (string, string) {
+	i := strings.Index(s, "  ")
+	if i < 0 {
+		return strings.TrimSpace(s), ""
+	}
+
+	return s[:i], strings.TrimSpace(s[i:])
+}
@@ -0,0 +1,63 @@
+package libsysinfo
+
+import (
+	"strings"
+
+	. "launchpad.net/gocheck"
+)
+
+type HwIDsTestSuite struct{}
+
+var (
+	_ = Suite(&HwIDsTestSuite{})
+)
+
+func (s *HwIDsTestSuite) TestParseIDDatabase_PCI(c *C) {
+	fixtures := `# comment
+8086  Intel Corporation
+	1521  I350 Gigabit Network Connection
+		8086 0001  Ethernet Server Adapter I350-T4
+		8086 00a1  Ethernet Server Adapter I350-T2
+15b3  Mellanox Technologies
+C 02  Network controller
+	00  Ethernet controller
+C 0c  Serial bus controller
+	03  USB controller
+		30  XHCI
+`
+	db, err := ParseIDDatabase(strings.NewReader(fixtures))
+	c.Assert(err, IsNil)
+
+	c.Assert(db.VendorName(0x8086), Equals, "Intel Corporation")
+	c.Assert(db.VendorName(0x15b3), Equals, "Mellanox Technologies")
+	c.Assert(db.VendorName(0x1234), Equals, "")
+	c.Assert(db.DeviceName(0x8086, 0x1521), Equals, "I350 Gigabit Network Connection")
+	c.Assert(db.DeviceName(0x8086, 0x1522), Equals, "")
+	c.Assert(db.SubsystemName(0x8086, 0x1521, 0x8086, 0x00a1), Equals, "Ethernet Server Adapter I350-T2")
+	c.Assert(db.SubsystemName(0x8086, 0x1521, 0x8086, 0x00a2), Equals, "")
+	c.Assert(db.ClassName(0x02), Equals, "Network controller")
+	c.Assert(db.SubclassName(0x02, 0x00), Equals, "Ethernet controller")
+	c.Assert(db.ProgIFName(0x0c, 0x03, 0x30), Equals, "XHCI")
+	c.Assert(db.ProgIFName(0x0c, 0x03, 0x20), Equals, "")
+}
+
+func (s *HwIDsTestSuite) TestParseIDDatabase_USB(c *C) {
+	fixtures := `0781  SanDisk Corp.
+	5581  Ultra
+		00  Mass Storage
+C 08  Mass Storage
+	06  SCSI
+		50  Bulk-Only
+AT 0409  Dummy language
+HID 00  None
+	01  Ignored
+`
+	db, err := ParseIDDatabase(strings.NewReader(fixtures))
+	c.Assert(err, IsNil)
+
+	c.Assert(db.DeviceName(0x0781, 0x5581), Equals, "Ultra")
+	c.Assert(db.InterfaceName(0x0781, 0x5581, 0), Equals, "Mass Storage")
+	c.Assert(db.ClassName(0x08), Equals, "Mass Storage")
+	c.Assert(db.ProgIFName(0x08, 0x06, 0x50), Equals, "Bulk-Only")
+	c.Assert(len(db.vendors), Equals, 1)
+}
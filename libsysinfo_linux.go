@@ -46,6 +46,7 @@ type CpuInfo struct {
 	Model          string
 	ModelName      string
 	Stepping       int
+	Microcode      string
 	CPUMHz         float64
 	CacheSize      int
 	CacheSizeUnit  string
@@ -60,10 +61,15 @@ type CpuInfo struct {
 	CpuIdLevel     int
 	Wp             string
 	Flags          []string
+	Bugs           []string
+	VMXFlags       []string
 	Bogomips       float64
 	ClflushSize    int
 	CacheAlignment int
 	AddressSizes   string
+
+	// Decoded from Flags on x86, from the Features line on ARM64
+	Features CPUFeatures
 }
 
 type LsbReleaseInfo struct {
@@ -91,6 +97,13 @@ type Meminfos struct {
 	SwapTotal  int
 	SwapFree   int
 
+	// Counted in pages, except Hugepagesize which is expressed in UnitUsed
+	HugePagesTotal int
+	HugePagesFree  int
+	HugePagesRsvd  int
+	HugePagesSurp  int
+	Hugepagesize   int
+
 	// The unit used in /proc/meminfo, lowered. Most likely always "kb"
 	UnitUsed string
 }
@@ -112,7 +125,7 @@ func Domain() (string, error) {
 	llv := &lazyLoadedValue{
 		CacheKey:    cacheKeys["DOMAIN_NAME"],
 		Fetcher:     getFullHostname,
-		Processor:   processDomainName,
+		Processor:   processDomainNameWithFallback,
 		CacheBucket: simpleValuesCache,
 	}
 
@@ -201,6 +214,26 @@ func CpuInfos() ([]CpuInfo, error) {
 	return processCpuInfos(buff), nil
 }
 
+// Returns the features supported by every processor. Hybrid processors may
+// report different features per core type.
+func SystemCPUFeatures() (CPUFeatures, error) {
+	cpus, err := CpuInfos()
+	if err != nil {
+		return CPUFeatures{}, err
+	}
+
+	if len(cpus) <= 0 {
+		return CPUFeatures{}, nil
+	}
+
+	features := cpus[0].Features
+	for _, cpu := range cpus[1:] {
+		features = features.Intersect(cpu.Features)
+	}
+
+	return features, nil
+}
+
 func NetworkInterfaces() ([]NetworkInterface, error) {
 	// XXX : switch to a cgo/iotctl based implementation
 	// XXX : parsing ifconfig's result is a PITA
@@ -323,6 +356,21 @@ func processDomainName(fullHostname string) (string, error) {
 	return fullHostname[pos+1:], nil
 }
 
+// When the host name is not fully qualified, fall back on the domain or
+// search entries of resolv.conf
+func processDomainNameWithFallback(fullHostname string) (string, error) {
+	return domainNameWithFallback(fullHostname, resolvConfPath)
+}
+
+func domainNameWithFallback(fullHostname string, resolvConf string) (string, error) {
+	d, err := processDomainName(fullHostname)
+	if err != ErrDomainNameNotFound {
+		return d, err
+	}
+
+	return resolverDomain(resolvConf)
+}
+
 func processHostname(fullHostname string) (string, error) {
 	pos := strings.Index(fullHostname, ".")
 	if pos == -1 {
@@ -419,6 +467,8 @@ func processCpuInfos(buff string) []CpuInfo {
 				tmp.ModelName = v
 			case "stepping":
 				tmp.Stepping = atoi(v)
+			case "microcode":
+				tmp.Microcode = v
 			case "cpu mhz":
 				tmp.CPUMHz = atof64(v)
 			case "cache size":
@@ -447,6 +497,14 @@ func processCpuInfos(buff string) []CpuInfo {
 				tmp.Wp = v
 			case "flags":
 				tmp.Flags = strings.Split(v, " ")
+				tmp.Features = parseCPUFeatures(tmp.Flags)
+			case "features":
+				// ARM64
+				tmp.Features = parseCPUFeatures(strings.Fields(v))
+			case "bugs":
+				tmp.Bugs = strings.Fields(v)
+			case "vmx flags":
+				tmp.VMXFlags = strings.Fields(v)
 			case "bogomips":
 				tmp.Bogomips = atof64(v)
 			case "clflush size":
@@ -542,7 +600,8 @@ func processMemInfos(buff string) Meminfos {
 		}
 
 		parts = strings.Fields(line)
-		if len(parts) != 3 {
+		// HugePages_* lines are page counts and have no unit
+		if len(parts) != 3 && len(parts) != 2 {
 			continue
 		}
 
@@ -551,7 +610,7 @@ func processMemInfos(buff string) Meminfos {
 		if v == "" {
 			continue
 		}
-		if mi.UnitUsed == "" {
+		if mi.UnitUsed == "" && len(parts) == 3 {
 			u = strings.TrimSpace(parts[2])
 			mi.UnitUsed = strings.ToLower(u)
 		}
@@ -571,6 +630,16 @@ func processMemInfos(buff string) Meminfos {
 			mi.SwapTotal = atoi(v)
 		case "swapfree":
 			mi.SwapFree = atoi(v)
+		case "hugepages_total":
+			mi.HugePagesTotal = atoi(v)
+		case "hugepages_free":
+			mi.HugePagesFree = atoi(v)
+		case "hugepages_rsvd":
+			mi.HugePagesRsvd = atoi(v)
+		case "hugepages_surp":
+			mi.HugePagesSurp = atoi(v)
+		case "hugepagesize":
+			mi.Hugepagesize = atoi(v)
 		default:
 			continue
 		}
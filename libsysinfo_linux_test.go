@@ -2,6 +2,7 @@ package libsysinfo
 
 import (
 	. "launchpad.net/gocheck"
+	"path/filepath"
 	"runtime"
 	"strings"
 )
@@ -47,6 +48,37 @@ func (s *LibSysInfoTestSuite) TestProcessDomainName_NotFound(c *C) {
 	c.Assert(obtained, Equals, "")
 }
 
+func (s *LibSysInfoTestSuite) TestDomainNameWithFallback(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"domain":      "nameserver 10.0.2.3\ndomain vagrantup.com\n",
+		"search":      "domain vagrantup.com\nsearch example.com example.org\n",
+		"search-only": "search .\n",
+	})
+
+	// a fully qualified host name wins over resolv.conf
+	obtained, err := domainNameWithFallback("wheezy64.example.net", filepath.Join(root, "domain"))
+	c.Assert(err, IsNil)
+	c.Assert(obtained, Equals, "example.net")
+
+	obtained, err = domainNameWithFallback("wheezy64", filepath.Join(root, "domain"))
+	c.Assert(err, IsNil)
+	c.Assert(obtained, Equals, "vagrantup.com")
+
+	obtained, err = domainNameWithFallback("wheezy64", filepath.Join(root, "search"))
+	c.Assert(err, IsNil)
+	c.Assert(obtained, Equals, "example.com")
+
+	obtained, err = domainNameWithFallback("wheezy64", filepath.Join(root, "search-only"))
+	c.Assert(err, Equals, ErrDomainNameNotFound)
+	c.Assert(obtained, Equals, "")
+
+	obtained, err = domainNameWithFallback("wheezy64", filepath.Join(root, "missing"))
+	c.Assert(err, Equals, ErrDomainNameNotFound)
+	c.Assert(obtained, Equals, "")
+}
+
 func (s *LibSysInfoTestSuite) TestProcessHostId(c *C) {
 	id := "007f0101"
 
@@ -218,6 +250,7 @@ power management:
 			ClflushSize:    64,
 			CacheAlignment: 64,
 			AddressSizes:   "36 bits physical, 48 bits virtual",
+			Features:       pentiumG630TFeatures,
 		},
 		CpuInfo{
 			Processor:     "1",
@@ -308,6 +341,7 @@ power management:
 			ClflushSize:    64,
 			CacheAlignment: 64,
 			AddressSizes:   "36 bits physical, 48 bits virtual",
+			Features:       pentiumG630TFeatures,
 		},
 	}
 
@@ -404,9 +438,9 @@ VmallocUsed:       17448 kB
 VmallocChunk:   34359719927 kB
 HardwareCorrupted:     0 kB
 AnonHugePages:         0 kB
-HugePages_Total:       0
-HugePages_Free:        0
-HugePages_Rsvd:        0
+HugePages_Total:       8
+HugePages_Free:        6
+HugePages_Rsvd:        1
 HugePages_Surp:        0
 Hugepagesize:       2048 kB
 DirectMap4k:       40896 kB
@@ -422,7 +456,14 @@ DirectMap2M:      221184 kB
 		SwapCached: 0,
 		SwapTotal:  466940,
 		SwapFree:   466940,
-		UnitUsed:   "kb",
+
+		HugePagesTotal: 8,
+		HugePagesFree:  6,
+		HugePagesRsvd:  1,
+		HugePagesSurp:  0,
+		Hugepagesize:   2048,
+
+		UnitUsed: "kb",
 	}
 
 	c.Assert(obtained, DeepEquals, expected)
@@ -0,0 +1,414 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// States of the members of an md array, as reported by
+// /sys/block/md*/md/dev-*/state
+const (
+	MDMemberInSync      = "in_sync"
+	MDMemberFaulty      = "faulty"
+	MDMemberSpare       = "spare"
+	MDMemberWriteMostly = "write_mostly"
+	MDMemberReplacement = "replacement"
+	MDMemberJournal     = "journal"
+)
+
+// Sync actions, see Documentation/admin-guide/md.rst
+const (
+	MDSyncIdle    = "idle"
+	MDSyncResync  = "resync"
+	MDSyncRecover = "recover"
+	MDSyncCheck   = "check"
+	MDSyncRepair  = "repair"
+	MDSyncReshape = "reshape"
+	MDSyncFrozen  = "frozen"
+)
+
+const (
+	mdStatPath = "/proc/mdstat"
+)
+
+var (
+	// e.g. "[=====>..]  recovery = 28.3% (296704/1046528) finish=0.5min speed=24720K/sec"
+	mdSyncRegexp = regexp.MustCompile(`(resync|recovery|check|repair|reshape)\s*=\s*([0-9.]+)%\s*\((\d+)/(\d+)\)(?:.*speed=(\d+)K/sec)?`)
+
+	// Flags of the members listed in /proc/mdstat, e.g. sdb1[2](F)
+	mdMemberFlags = map[string]string{
+		"F": MDMemberFaulty,
+		"S": MDMemberSpare,
+		"W": MDMemberWriteMostly,
+		"R": MDMemberReplacement,
+		"J": MDMemberJournal,
+	}
+)
+
+// ----
+
+// A Linux software RAID array
+type MDArray struct {
+	// e.g. md0
+	Name string
+
+	// e.g. raid1, raid5, linear
+	Level string
+
+	// e.g. clean, active, inactive, readonly, read-auto
+	State string
+
+	Active   bool
+	ReadOnly bool
+
+	// Superblock format, e.g. 1.2
+	Metadata string
+	UUID     string
+
+	// In bytes
+	Size      uint64
+	ChunkSize uint64
+
+	// Number of devices the array is made of when complete
+	RaidDisks int
+
+	// Number of devices missing from the array
+	Degraded int
+
+	// One of the MDSync* constants
+	SyncAction string
+
+	// Progress of the running sync action. Positions are in bytes, the speed
+	// in bytes/s.
+	SyncCompleted uint64
+	SyncTotal     uint64
+	SyncSpeed     uint64
+
+	// Sectors found inconsistent by the last check or repair
+	MismatchCount uint64
+
+	Members []MDMember
+}
+
+type MDMember struct {
+	// e.g. sda1
+	Name string
+
+	// Role in the array, -1 for spares, faulty devices or when unknown
+	Slot int
+
+	// MDMember* constants
+	States []string
+
+	// Read errors corrected on the device
+	Errors int
+}
+
+// ----
+
+// Returns the md arrays, an empty list when the md driver is not loaded
+func MDArrays() ([]MDArray, error) {
+	buff, err := readFile(mdStatPath)
+	if os.IsNotExist(err) {
+		return []MDArray(nil), nil
+	}
+	if err != nil {
+		return []MDArray(nil), err
+	}
+
+	arrays := processMDStat(buff)
+	for i, a := range arrays {
+		arrays[i] = readMDArray(sysBlockPath, a)
+	}
+
+	return arrays, nil
+}
+
+// Reports whether the array misses some of its devices
+func (a MDArray) IsDegraded() bool {
+	return a.Degraded > 0
+}
+
+// Progress of the running sync action in percent, 0 when idle
+func (a MDArray) SyncProgress() float64 {
+	if a.SyncTotal == 0 {
+		return 0
+	}
+
+	return float64(a.SyncCompleted) * 100 / float64(a.SyncTotal)
+}
+
+func (m MDMember) HasState(state string) bool {
+	for _, s := range m.States {
+		if s == state {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ----
+
+func processMDStat(buff string) []MDArray {
+	var arrays []MDArray
+	var a *MDArray
+
+	for _, line := range strings.Split(buff, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) <= 0 {
+			continue
+		}
+
+		// "md0 : active raid1 sdb1[1] sda1[0]"
+		if strings.HasPrefix(fields[0], "md") && len(fields) >= 3 && fields[1] == ":" {
+			arrays = append(arrays, processMDStatArrayLine(fields))
+			a = &arrays[len(arrays)-1]
+			continue
+		}
+
+		if a == nil || line[0] != ' ' && line[0] != '\t' {
+			a = nil
+			continue
+		}
+
+		processMDStatDetailLine(a, fields, line)
+	}
+
+	return arrays
+}
+
+func processMDStatArrayLine(fields []string) MDArray {
+	a := MDArray{
+		Name:       fields[0],
+		State:      fields[2],
+		Active:     fields[2] == "active",
+		SyncAction: MDSyncIdle,
+	}
+
+	rest := fields[3:]
+	for len(rest) > 0 && strings.HasPrefix(rest[0], "(") {
+		if strings.Contains(rest[0], "read-only") {
+			a.ReadOnly = true
+		}
+		rest = rest[1:]
+	}
+
+	// inactive arrays do not report their level
+	if len(rest) > 0 && !strings.Contains(rest[0], "[") {
+		a.Level = rest[0]
+		rest = rest[1:]
+	}
+
+	for _, member := range rest {
+		a.Members = append(a.Members, processMDStatMember(member))
+	}
+
+	return a
+}
+
+// Parses a member such as sdb1[2](F)
+func processMDStatMember(s string) MDMember {
+	m := MDMember{Name: s, Slot: -1}
+
+	i := strings.Index(s, "[")
+	j := strings.Index(s, "]")
+	if i < 0 || j < i {
+		return m
+	}
+	m.Name = s[:i]
+
+	for _, flag := range strings.Split(s[j:], "(")[1:] {
+		state, exists := mdMemberFlags[strings.TrimSuffix(flag, ")")]
+		if exists {
+			m.States = append(m.States, state)
+		}
+	}
+
+	// the number is the descriptor of the device, which matches its role
+	// until devices get replaced, sysfs then gives the actual role. Spares
+	// and faulty devices keep a number but have no role.
+	slot, err := strconv.Atoi(s[i+1 : j])
+	if err == nil && !m.HasState(MDMemberSpare) && !m.HasState(MDMemberFaulty) {
+		m.Slot = slot
+	}
+
+	return m
+}
+
+// Marks the members whose role is up in a status such as [U_U] as in sync
+func processMDStatRoles(a *MDArray, status string) {
+	for i, m := range a.Members {
+		if m.Slot < 0 || m.Slot >= len(status) || len(m.States) > 0 {
+			continue
+		}
+
+		if status[m.Slot] == 'U' {
+			a.Members[i].States = []string{MDMemberInSync}
+		}
+	}
+}
+
+// Parses the lines following the array line, e.g.
+//
+//	2093056 blocks super 1.2 level 5, 512k chunk, algorithm 2 [3/2] [U_U]
+//	[=====>...............]  recovery = 28.3% (296704/1046528) finish=0.5min speed=24720K/sec
+func processMDStatDetailLine(a *MDArray, fields []string, line string) {
+	if len(fields) >= 2 && fields[1] == "blocks" {
+		blocks, _ := strconv.ParseUint(fields[0], 10, 64)
+		a.Size = blocks * 1024
+
+		for i, f := range fields {
+			switch {
+			case f == "super" && i+1 < len(fields):
+				a.Metadata = fields[i+1]
+
+			case (f == "chunk," || f == "chunk" || f == "chunks") && i > 0:
+				a.ChunkSize = parseMDChunkSize(fields[i-1])
+
+			case strings.HasPrefix(f, "[") && strings.Contains(f, "/"):
+				disks := strings.Split(strings.Trim(f, "[]"), "/")
+				if len(disks) != 2 {
+					continue
+				}
+
+				total, err1 := strconv.Atoi(disks[0])
+				working, err2 := strconv.Atoi(disks[1])
+				if err1 != nil || err2 != nil {
+					continue
+				}
+
+				a.RaidDisks = total
+				a.Degraded = total - working
+
+			case strings.HasPrefix(f, "[") && strings.Trim(f, "[U_]") == "":
+				processMDStatRoles(a, strings.Trim(f, "[]"))
+			}
+		}
+
+		return
+	}
+
+	m := mdSyncRegexp.FindStringSubmatch(line)
+	if m == nil {
+		return
+	}
+
+	a.SyncAction = m[1]
+	if a.SyncAction == "recovery" {
+		a.SyncAction = MDSyncRecover
+	}
+
+	completed, _ := strconv.ParseUint(m[3], 10, 64)
+	total, _ := strconv.ParseUint(m[4], 10, 64)
+	speed, _ := strconv.ParseUint(m[5], 10, 64)
+
+	a.SyncCompleted = completed * 1024
+	a.SyncTotal = total * 1024
+	a.SyncSpeed = speed * 1024
+}
+
+// Parses chunk sizes such as 512k
+func parseMDChunkSize(s string) uint64 {
+	v, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimSuffix(s, ","), "k"), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return v * 1024
+}
+
+// Completes an array parsed from /proc/mdstat with the attributes found in
+// sysfs, which are more precise
+func readMDArray(root string, a MDArray) MDArray {
+	dir := filepath.Join(root, a.Name, "md")
+
+	_, err := os.Stat(dir)
+	if err != nil {
+		return a
+	}
+
+	if v := sysfsString(filepath.Join(dir, "level")); v != "" {
+		a.Level = v
+	}
+	if v := sysfsString(filepath.Join(dir, "array_state")); v != "" {
+		a.State = v
+	}
+	if v := sysfsString(filepath.Join(dir, "metadata_version")); v != "" {
+		a.Metadata = v
+	}
+	if v := sysfsUint64(filepath.Join(root, a.Name, "size")); v > 0 {
+		a.Size = v * sysfsSectorSize
+	}
+	if v := sysfsInt(filepath.Join(dir, "raid_disks")); v > 0 {
+		a.RaidDisks = v
+	}
+
+	a.UUID = sysfsString(filepath.Join(dir, "uuid"))
+	a.MismatchCount = sysfsUint64(filepath.Join(dir, "mismatch_cnt"))
+
+	// only striped levels have a chunk size, the attribute is 0 otherwise
+	_, err = os.Stat(filepath.Join(dir, "chunk_size"))
+	if err == nil {
+		a.ChunkSize = sysfsUint64(filepath.Join(dir, "chunk_size"))
+	}
+
+	_, err = os.Stat(filepath.Join(dir, "degraded"))
+	if err == nil {
+		a.Degraded = sysfsInt(filepath.Join(dir, "degraded"))
+	}
+
+	if v := sysfsString(filepath.Join(dir, "sync_action")); v != "" {
+		a.SyncAction = v
+	}
+
+	// "none" when idle, "completed / total" in sectors otherwise
+	completed := strings.Split(sysfsString(filepath.Join(dir, "sync_completed")), "/")
+	if len(completed) == 2 {
+		c, err1 := strconv.ParseUint(strings.TrimSpace(completed[0]), 10, 64)
+		t, err2 := strconv.ParseUint(strings.TrimSpace(completed[1]), 10, 64)
+		if err1 == nil && err2 == nil {
+			a.SyncCompleted = c * sysfsSectorSize
+			a.SyncTotal = t * sysfsSectorSize
+		}
+	} else if a.SyncAction == MDSyncIdle {
+		a.SyncCompleted, a.SyncTotal, a.SyncSpeed = 0, 0, 0
+	}
+
+	// in K/sec, "none" when idle
+	speed, err := strconv.ParseUint(sysfsString(filepath.Join(dir, "sync_speed")), 10, 64)
+	if err == nil {
+		a.SyncSpeed = speed * 1024
+	}
+
+	for i, m := range a.Members {
+		a.Members[i] = readMDMember(dir, m)
+	}
+
+	return a
+}
+
+func readMDMember(dir string, m MDMember) MDMember {
+	devDir := filepath.Join(dir, "dev-"+m.Name)
+
+	state := sysfsString(filepath.Join(devDir, "state"))
+	if state == "" {
+		return m
+	}
+
+	m.States = strings.Split(state, ",")
+	m.Errors = sysfsInt(filepath.Join(devDir, "errors"))
+
+	// "none" for spares
+	slot, err := strconv.Atoi(sysfsString(filepath.Join(devDir, "slot")))
+	if err == nil {
+		m.Slot = slot
+	}
+
+	return m
+}
@@ -0,0 +1,156 @@
+package libsysinfo
+
+import (
+	. "launchpad.net/gocheck"
+)
+
+type MDTestSuite struct{}
+
+var (
+	_ = Suite(&MDTestSuite{})
+)
+
+const mdStatFixture = `Personalities : [raid1] [raid6] [raid5] [raid4] [raid0]
+md1 : active raid5 sdd1[3] sde1[2] sdc1[1] sdb2[0](F)
+      2093056 blocks super 1.2 level 5, 512k chunk, algorithm 2 [3/2] [_UU]
+      [=====>...............]  recovery = 28.3% (296704/1046528) finish=0.5min speed=24720K/sec
+
+md0 : active (auto-read-only) raid1 sdb1[1] sda1[0]
+      1046528 blocks super 1.2 [2/2] [UU]
+
+md3 : active raid0 sdf1[1] sde1[0]
+      2093056 blocks super 1.2 64k chunks
+
+md2 : inactive sdg1[0](S)
+      1046528 blocks super 1.2
+
+unused devices: <none>
+`
+
+func (s *MDTestSuite) TestProcessMDStat(c *C) {
+	arrays := processMDStat(mdStatFixture)
+	c.Assert(arrays, HasLen, 4)
+
+	md1 := arrays[0]
+	c.Check(md1.Name, Equals, "md1")
+	c.Check(md1.Level, Equals, "raid5")
+	c.Check(md1.State, Equals, "active")
+	c.Check(md1.Active, Equals, true)
+	c.Check(md1.ReadOnly, Equals, false)
+	c.Check(md1.Metadata, Equals, "1.2")
+	c.Check(md1.Size, Equals, uint64(2093056*1024))
+	c.Check(md1.ChunkSize, Equals, uint64(512*1024))
+	c.Check(md1.RaidDisks, Equals, 3)
+	c.Check(md1.Degraded, Equals, 1)
+	c.Check(md1.IsDegraded(), Equals, true)
+	c.Check(md1.SyncAction, Equals, MDSyncRecover)
+	c.Check(md1.SyncCompleted, Equals, uint64(296704*1024))
+	c.Check(md1.SyncTotal, Equals, uint64(1046528*1024))
+	c.Check(md1.SyncSpeed, Equals, uint64(24720*1024))
+	c.Check(int(md1.SyncProgress()*10), Equals, 283)
+	c.Check(md1.Members, DeepEquals, []MDMember{
+		{Name: "sdd1", Slot: 3},
+		{Name: "sde1", Slot: 2, States: []string{MDMemberInSync}},
+		{Name: "sdc1", Slot: 1, States: []string{MDMemberInSync}},
+		{Name: "sdb2", Slot: -1, States: []string{MDMemberFaulty}},
+	})
+
+	md0 := arrays[1]
+	c.Check(md0.Level, Equals, "raid1")
+	c.Check(md0.ReadOnly, Equals, true)
+	c.Check(md0.ChunkSize, Equals, uint64(0))
+	c.Check(md0.IsDegraded(), Equals, false)
+	c.Check(md0.SyncAction, Equals, MDSyncIdle)
+	c.Check(md0.SyncProgress(), Equals, 0.0)
+	c.Check(md0.Members, DeepEquals, []MDMember{
+		{Name: "sdb1", Slot: 1, States: []string{MDMemberInSync}},
+		{Name: "sda1", Slot: 0, States: []string{MDMemberInSync}},
+	})
+
+	md3 := arrays[2]
+	c.Check(md3.Level, Equals, "raid0")
+	c.Check(md3.ChunkSize, Equals, uint64(64*1024))
+	c.Check(md3.RaidDisks, Equals, 0)
+
+	md2 := arrays[3]
+	c.Check(md2.State, Equals, "inactive")
+	c.Check(md2.Active, Equals, false)
+	c.Check(md2.Level, Equals, "")
+	c.Check(md2.Members, DeepEquals, []MDMember{
+		{Name: "sdg1", Slot: -1, States: []string{MDMemberSpare}},
+	})
+}
+
+func (s *MDTestSuite) TestReadMDArray(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"md1/size":                   "4186112\n",
+		"md1/md/level":               "raid5\n",
+		"md1/md/array_state":         "clean\n",
+		"md1/md/metadata_version":    "1.2\n",
+		"md1/md/uuid":                "2b5a0a6e-7f2c-4c4e-9d2b-0e9f6c6f1a11\n",
+		"md1/md/raid_disks":          "3\n",
+		"md1/md/chunk_size":          "524288\n",
+		"md1/md/degraded":            "1\n",
+		"md1/md/sync_action":         "recover\n",
+		"md1/md/sync_completed":      "600000 / 2093056\n",
+		"md1/md/sync_speed":          "25000\n",
+		"md1/md/mismatch_cnt":        "0\n",
+		"md1/md/dev-sdd1/state":      "spare\n",
+		"md1/md/dev-sdd1/slot":       "0\n",
+		"md1/md/dev-sdc1/state":      "in_sync\n",
+		"md1/md/dev-sdc1/slot":       "1\n",
+		"md1/md/dev-sdc1/errors":     "3\n",
+		"md1/md/dev-sdb2/state":      "faulty,write_error\n",
+		"md1/md/dev-sdb2/slot":       "none\n",
+		"md0/md/sync_action":         "idle\n",
+		"md0/md/sync_completed":      "none\n",
+		"md0/md/degraded":            "0\n",
+		"md0/md/dev-sda1/state":      "in_sync,write_mostly\n",
+		"md0/md/dev-sda1/slot":       "0\n",
+		"md0/md/dev-sdb1/state":      "in_sync\n",
+		"md0/md/dev-sdb1/slot":       "1\n",
+		"md0/md/array_state":         "read-auto\n",
+		"md0/md/metadata_version":    "1.2\n",
+		"md0/md/mismatch_cnt":        "128\n",
+		"md0/md/raid_disks":          "2\n",
+		"md0/md/level":               "raid1\n",
+		"md0/md/dev-sda1/errors":     "0\n",
+		"md0/md/dev-sdb1/errors":     "0\n",
+		"md0/md/sync_speed":          "none\n",
+		"md0/md/uuid":                "",
+		"md0/size":                   "2093056\n",
+		"md0/md/dev-sdb1/bad_blocks": "",
+	})
+
+	arrays := processMDStat(mdStatFixture)
+
+	md1 := readMDArray(root, arrays[0])
+	c.Check(md1.State, Equals, "clean")
+	c.Check(md1.UUID, Equals, "2b5a0a6e-7f2c-4c4e-9d2b-0e9f6c6f1a11")
+	c.Check(md1.Size, Equals, uint64(4186112*512))
+	c.Check(md1.ChunkSize, Equals, uint64(524288))
+	c.Check(md1.Degraded, Equals, 1)
+	c.Check(md1.SyncAction, Equals, MDSyncRecover)
+	c.Check(md1.SyncCompleted, Equals, uint64(600000*512))
+	c.Check(md1.SyncTotal, Equals, uint64(2093056*512))
+	c.Check(md1.SyncSpeed, Equals, uint64(25000*1024))
+	c.Check(md1.Members, DeepEquals, []MDMember{
+		{Name: "sdd1", Slot: 0, States: []string{MDMemberSpare}},
+		{Name: "sde1", Slot: 2, States: []string{MDMemberInSync}},
+		{Name: "sdc1", Slot: 1, States: []string{MDMemberInSync}, Errors: 3},
+		{Name: "sdb2", Slot: -1, States: []string{MDMemberFaulty, "write_error"}},
+	})
+	c.Check(md1.Members[3].HasState(MDMemberFaulty), Equals, true)
+
+	md0 := readMDArray(root, arrays[1])
+	c.Check(md0.State, Equals, "read-auto")
+	c.Check(md0.MismatchCount, Equals, uint64(128))
+	c.Check(md0.SyncAction, Equals, MDSyncIdle)
+	c.Check(md0.SyncTotal, Equals, uint64(0))
+	c.Check(md0.Members[1].HasState(MDMemberWriteMostly), Equals, true)
+
+	// arrays missing from sysfs are left untouched
+	c.Check(readMDArray(root, arrays[2]), DeepEquals, arrays[2])
+}
@@ -0,0 +1,263 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"net"
+	"strings"
+	"syscall"
+)
+
+// ARP flags as printed in /proc/net/arp, see include/uapi/linux/if_arp.h
+const (
+	arpComplete  = 0x02
+	arpPermanent = 0x04
+	arpPublished = 0x08
+)
+
+// Neighbor discovery states, see include/uapi/linux/neighbour.h
+const (
+	nudIncomplete = 0x01
+	nudReachable  = 0x02
+	nudStale      = 0x04
+	nudDelay      = 0x08
+	nudProbe      = 0x10
+	nudFailed     = 0x20
+	nudNoArp      = 0x40
+	nudPermanent  = 0x80
+)
+
+const (
+	NeighborIncomplete NeighborState = iota
+	NeighborComplete
+	NeighborStale
+	NeighborFailed
+	NeighborPermanent
+)
+
+const (
+	// sizeof(struct ndmsg)
+	ndMsgLen = 12
+
+	ndaDst    = 1
+	ndaLLAddr = 2
+)
+
+// ----
+
+type NeighborState int
+
+func (s NeighborState) String() string {
+	switch s {
+	case NeighborIncomplete:
+		return "incomplete"
+	case NeighborComplete:
+		return "complete"
+	case NeighborStale:
+		return "stale"
+	case NeighborFailed:
+		return "failed"
+	case NeighborPermanent:
+		return "permanent"
+	}
+
+	return "unknown"
+}
+
+type Neighbor struct {
+	// Either FamilyIPv4 or FamilyIPv6
+	Family    int
+	IP        net.IP
+	HWAddr    net.HardwareAddr
+	Interface string
+	State     NeighborState
+
+	// Whether the host answers for this address on behalf of the peer (proxy
+	// ARP), IPv4 only
+	Published bool
+
+	// Only available for IPv4 entries
+	HWType int
+	Mask   string
+}
+
+// Tells whether the link layer address of the peer is known
+func (n Neighbor) Resolved() bool {
+	switch n.State {
+	case NeighborComplete, NeighborStale, NeighborPermanent:
+		return true
+	}
+
+	return false
+}
+
+// ----
+
+func Neighbors() ([]Neighbor, error) {
+	buff, err := getARPTable()
+	if err != nil {
+		return []Neighbor(nil), err
+	}
+
+	neighbors := processARPTable(buff)
+
+	msgs, err := getIPv6Neighbors()
+	if err != nil {
+		return neighbors, err
+	}
+
+	return append(neighbors, processNeighborMessages(msgs, ifaceName)...), nil
+}
+
+// Returns the peers whose link layer address has been resolved through this
+// network interface
+func (nif NetworkInterface) Neighbors() ([]Neighbor, error) {
+	var out []Neighbor
+
+	all, err := Neighbors()
+	if err != nil {
+		return out, err
+	}
+
+	for _, n := range all {
+		if n.Interface == nif.Name && n.Resolved() {
+			out = append(out, n)
+		}
+	}
+
+	return out, nil
+}
+
+// ----
+
+func ifaceName(index int) string {
+	iface, err := net.InterfaceByIndex(index)
+	if err != nil {
+		return ""
+	}
+
+	return iface.Name
+}
+
+func arpState(flags int) NeighborState {
+	if flags&arpPermanent != 0 {
+		return NeighborPermanent
+	}
+
+	if flags&arpComplete != 0 {
+		return NeighborComplete
+	}
+
+	return NeighborIncomplete
+}
+
+func nudState(state int) NeighborState {
+	switch {
+	case state&(nudPermanent|nudNoArp) != 0:
+		return NeighborPermanent
+	case state&(nudReachable|nudDelay|nudProbe) != 0:
+		return NeighborComplete
+	case state&nudStale != 0:
+		return NeighborStale
+	case state&nudFailed != 0:
+		return NeighborFailed
+	}
+
+	return NeighborIncomplete
+}
+
+func processARPTable(buff string) []Neighbor {
+	var neighbors []Neighbor
+
+	for i, line := range strings.Split(buff, "\n") {
+		// first line holds the column names
+		if i == 0 {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			continue
+		}
+
+		flags := int(hextoui64(strings.TrimPrefix(fields[2], "0x")))
+		hwAddr, _ := net.ParseMAC(fields[3])
+
+		neighbors = append(neighbors, Neighbor{
+			Family:    FamilyIPv4,
+			IP:        net.ParseIP(fields[0]).To4(),
+			HWType:    int(hextoui64(strings.TrimPrefix(fields[1], "0x"))),
+			HWAddr:    hwAddr,
+			Mask:      fields[4],
+			Interface: fields[5],
+			State:     arpState(flags),
+			Published: flags&arpPublished != 0,
+		})
+	}
+
+	return neighbors
+}
+
+func processNeighborMessages(msgs []syscall.NetlinkMessage, ifname func(int) string) []Neighbor {
+	var neighbors []Neighbor
+
+	for _, m := range msgs {
+		if m.Header.Type != syscall.RTM_NEWNEIGH || len(m.Data) < ndMsgLen {
+			continue
+		}
+
+		n := Neighbor{
+			Family:    FamilyIPv6,
+			Interface: ifname(int(int32(nativeEndian.Uint32(m.Data[4:8])))),
+			State:     nudState(int(nativeEndian.Uint16(m.Data[8:10]))),
+		}
+
+		attrs := m.Data[ndMsgLen:]
+		for len(attrs) >= syscall.SizeofRtAttr {
+			l := int(nativeEndian.Uint16(attrs[0:2]))
+			t := int(nativeEndian.Uint16(attrs[2:4]))
+			if l < syscall.SizeofRtAttr || l > len(attrs) {
+				break
+			}
+
+			v := attrs[syscall.SizeofRtAttr:l]
+			switch t {
+			case ndaDst:
+				n.IP = net.IP(append([]byte(nil), v...))
+			case ndaLLAddr:
+				n.HWAddr = net.HardwareAddr(append([]byte(nil), v...))
+			}
+
+			// attributes are aligned on 4 bytes
+			l = (l + syscall.RTA_ALIGNTO - 1) & ^(syscall.RTA_ALIGNTO - 1)
+			if l > len(attrs) {
+				break
+			}
+			attrs = attrs[l:]
+		}
+
+		if n.IP == nil {
+			continue
+		}
+
+		neighbors = append(neighbors, n)
+	}
+
+	return neighbors
+}
+
+// ----
+
+func getARPTable() (string, error) {
+	return readFile("/proc/net/arp")
+}
+
+func getIPv6Neighbors() ([]syscall.NetlinkMessage, error) {
+	// XXX : there is no procfs equivalent of /proc/net/arp for IPv6
+	rib, err := syscall.NetlinkRIB(syscall.RTM_GETNEIGH, syscall.AF_INET6)
+	if err != nil {
+		return []syscall.NetlinkMessage(nil), err
+	}
+
+	return syscall.ParseNetlinkMessage(rib)
+}
@@ -0,0 +1,111 @@
+package libsysinfo
+
+import (
+	. "launchpad.net/gocheck"
+	"net"
+	"syscall"
+)
+
+type NeighborsTestSuite struct{}
+
+var (
+	_ = Suite(&NeighborsTestSuite{})
+)
+
+func (s *NeighborsTestSuite) TestProcessARPTable(c *C) {
+	fixture := `IP address       HW type     Flags       HW address            Mask     Device
+10.0.2.2         0x1         0x2         52:54:00:12:35:02     *        eth0
+10.0.2.3         0x1         0x0         00:00:00:00:00:00     *        eth0
+192.168.1.10     0x1         0x6         08:00:27:b3:27:23     *        wlan0
+192.168.1.20     0x1         0xc         08:00:27:b3:27:24     *        wlan0
+`
+	obtained := processARPTable(fixture)
+
+	c.Assert(len(obtained), Equals, 4)
+
+	mac, _ := net.ParseMAC("52:54:00:12:35:02")
+	c.Assert(obtained[0], DeepEquals, Neighbor{
+		Family:    FamilyIPv4,
+		IP:        net.IP{10, 0, 2, 2},
+		HWType:    1,
+		HWAddr:    mac,
+		Mask:      "*",
+		Interface: "eth0",
+		State:     NeighborComplete,
+	})
+
+	c.Assert(obtained[1].State, Equals, NeighborIncomplete)
+	c.Assert(obtained[1].Resolved(), Equals, false)
+	c.Assert(obtained[2].State, Equals, NeighborPermanent)
+	c.Assert(obtained[2].Published, Equals, false)
+	c.Assert(obtained[3].State, Equals, NeighborPermanent)
+	c.Assert(obtained[3].Published, Equals, true)
+}
+
+func (s *NeighborsTestSuite) TestProcessNeighborMessages(c *C) {
+	ip := net.ParseIP("fe80::1")
+	mac, _ := net.ParseMAC("52:54:00:12:35:02")
+
+	msgs := []syscall.NetlinkMessage{
+		neighborMessage(2, nudReachable, ip, mac),
+		neighborMessage(2, nudStale, net.ParseIP("fe80::2"), mac),
+		neighborMessage(3, nudFailed, net.ParseIP("fe80::3"), nil),
+		syscall.NetlinkMessage{
+			Header: syscall.NlMsghdr{Type: syscall.NLMSG_DONE},
+		},
+	}
+
+	ifname := func(i int) string {
+		return map[int]string{2: "eth0", 3: "wlan0"}[i]
+	}
+
+	obtained := processNeighborMessages(msgs, ifname)
+
+	c.Assert(len(obtained), Equals, 3)
+	c.Assert(obtained[0], DeepEquals, Neighbor{
+		Family:    FamilyIPv6,
+		IP:        ip,
+		HWAddr:    mac,
+		Interface: "eth0",
+		State:     NeighborComplete,
+	})
+
+	c.Assert(obtained[1].State, Equals, NeighborStale)
+	c.Assert(obtained[1].Resolved(), Equals, true)
+	c.Assert(obtained[2].Interface, Equals, "wlan0")
+	c.Assert(obtained[2].State, Equals, NeighborFailed)
+	c.Assert(obtained[2].HWAddr, IsNil)
+}
+
+func (s *NeighborsTestSuite) TestNeighborStateString(c *C) {
+	c.Assert(NeighborIncomplete.String(), Equals, "incomplete")
+	c.Assert(NeighborPermanent.String(), Equals, "permanent")
+	c.Assert(NeighborState(42).String(), Equals, "unknown")
+}
+
+func neighborMessage(index int, state int, ip net.IP, mac net.HardwareAddr) syscall.NetlinkMessage {
+	data := make([]byte, ndMsgLen)
+	data[0] = syscall.AF_INET6
+	nativeEndian.PutUint32(data[4:8], uint32(index))
+	nativeEndian.PutUint16(data[8:10], uint16(state))
+
+	data = append(data, rtAttr(ndaDst, ip)...)
+	if mac != nil {
+		data = append(data, rtAttr(ndaLLAddr, mac)...)
+	}
+
+	return syscall.NetlinkMessage{
+		Header: syscall.NlMsghdr{Type: syscall.RTM_NEWNEIGH},
+		Data:   data,
+	}
+}
+
+func rtAttr(t int, v []byte) []byte {
+	l := syscall.SizeofRtAttr + len(v)
+	attr := make([]byte, (l+syscall.RTA_ALIGNTO-1) & ^(syscall.RTA_ALIGNTO-1))
+	nativeEndian.PutUint16(attr[0:2], uint16(l))
+	nativeEndian.PutUint16(attr[2:4], uint16(t))
+	copy(attr[syscall.SizeofRtAttr:], v)
+
+	return attr
+}
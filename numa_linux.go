@@ -0,0 +1,199 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	sysNodePath = "/sys/devices/system/node"
+)
+
+var (
+	ErrNUMANotAvailable = &LibSysInfoErr{"NUMA informations not available"}
+)
+
+// ----
+
+type NUMANode struct {
+	ID   int
+	CPUs []int
+
+	// In bytes
+	MemTotal uint64
+	MemFree  uint64
+	MemUsed  uint64
+
+	// Allocation counters from numastat, in pages
+	NumaHit       uint64
+	NumaMiss      uint64
+	NumaForeign   uint64
+	InterleaveHit uint64
+	LocalNode     uint64
+	OtherNode     uint64
+
+	// Relative distance to every node, in the order NUMANodes() returns them.
+	// The distance to the node itself is usually 10.
+	Distances []int
+
+	HugePages []HugePagePool
+}
+
+// A pool of huge pages of a given size. Counters are in pages.
+type HugePagePool struct {
+	// In bytes
+	PageSize uint64
+
+	Total   uint64
+	Free    uint64
+	Surplus uint64
+
+	// Not available per NUMA node
+	Reserved   uint64
+	Overcommit uint64
+}
+
+// ----
+
+// Returns the online NUMA nodes. Systems without NUMA support report a single
+// node when the kernel is built with CONFIG_NUMA.
+func NUMANodes() ([]NUMANode, error) {
+	var nodes []NUMANode
+
+	_, err := os.Stat(sysNodePath)
+	if os.IsNotExist(err) {
+		return nodes, ErrNUMANotAvailable
+	}
+	if err != nil {
+		return nodes, err
+	}
+
+	return readNUMANodes(sysNodePath), nil
+}
+
+// ----
+
+func readNUMANodes(root string) []NUMANode {
+	var nodes []NUMANode
+
+	online := parseCPUList(sysfsString(filepath.Join(root, "online")))
+	if len(online) <= 0 {
+		// the online file appeared in 2.6.30
+		for _, name := range dirNames(root) {
+			id, err := strconv.Atoi(strings.TrimPrefix(name, "node"))
+			if strings.HasPrefix(name, "node") && err == nil {
+				online = append(online, id)
+			}
+		}
+
+		// dirNames sorts node10 before node2
+		sort.Ints(online)
+	}
+
+	for _, id := range online {
+		nodes = append(nodes, readNUMANode(root, id))
+	}
+
+	return nodes
+}
+
+func readNUMANode(root string, id int) NUMANode {
+	dir := filepath.Join(root, "node"+strconv.Itoa(id))
+
+	n := NUMANode{
+		ID:        id,
+		CPUs:      parseCPUList(sysfsString(filepath.Join(dir, "cpulist"))),
+		HugePages: readHugePagePools(filepath.Join(dir, "hugepages")),
+	}
+
+	for _, d := range strings.Fields(sysfsString(filepath.Join(dir, "distance"))) {
+		n.Distances = append(n.Distances, atoi(d))
+	}
+
+	meminfo := processNodeMemInfo(sysfsString(filepath.Join(dir, "meminfo")))
+	n.MemTotal = meminfo["MemTotal"]
+	n.MemFree = meminfo["MemFree"]
+	n.MemUsed = meminfo["MemUsed"]
+
+	numastat := processKeyValues(sysfsString(filepath.Join(dir, "numastat")))
+	n.NumaHit = numastat["numa_hit"]
+	n.NumaMiss = numastat["numa_miss"]
+	n.NumaForeign = numastat["numa_foreign"]
+	n.InterleaveHit = numastat["interleave_hit"]
+	n.LocalNode = numastat["local_node"]
+	n.OtherNode = numastat["other_node"]
+
+	return n
+}
+
+// Returns the page size in bytes of a hugepages-<size>kB directory
+func parseHugePagesDirName(name string) (uint64, bool) {
+	const prefix = "hugepages-"
+	const suffix = "kB"
+
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+		return 0, false
+	}
+
+	size, err := strconv.ParseUint(name[len(prefix):len(name)-len(suffix)], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return size * 1024, true
+}
+
+// Reads the hugepages-<size>kB directories found in dir
+func readHugePagePools(dir string) []HugePagePool {
+	var pools []HugePagePool
+
+	for _, name := range dirNames(dir) {
+		size, ok := parseHugePagesDirName(name)
+		if !ok {
+			continue
+		}
+
+		poolDir := filepath.Join(dir, name)
+
+		pools = append(pools, HugePagePool{
+			PageSize:   size,
+			Total:      sysfsUint64(filepath.Join(poolDir, "nr_hugepages")),
+			Free:       sysfsUint64(filepath.Join(poolDir, "free_hugepages")),
+			Surplus:    sysfsUint64(filepath.Join(poolDir, "surplus_hugepages")),
+			Reserved:   sysfsUint64(filepath.Join(poolDir, "resv_hugepages")),
+			Overcommit: sysfsUint64(filepath.Join(poolDir, "nr_overcommit_hugepages")),
+		})
+	}
+
+	return pools
+}
+
+// Parses "Node 0 MemTotal: 4423416 kB" lines, values are returned in bytes
+func processNodeMemInfo(buff string) map[string]uint64 {
+	values := make(map[string]uint64)
+
+	for _, line := range strings.Split(buff, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[0] != "Node" {
+			continue
+		}
+
+		v, err := strconv.ParseUint(fields[3], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		if len(fields) == 5 && strings.ToLower(fields[4]) == "kb" {
+			v *= 1024
+		}
+
+		values[strings.TrimSuffix(fields[2], ":")] = v
+	}
+
+	return values
+}
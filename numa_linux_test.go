@@ -0,0 +1,92 @@
+package libsysinfo
+
+import (
+	. "launchpad.net/gocheck"
+)
+
+type NUMATestSuite struct{}
+
+var (
+	_ = Suite(&NUMATestSuite{})
+)
+
+func (s *NUMATestSuite) TestReadNUMANodes(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"online":         "0-1\n",
+		"node0/cpulist":  "0-3,8-11\n",
+		"node0/distance": "10 21\n",
+		"node0/meminfo": `Node 0 MemTotal:       32825132 kB
+Node 0 MemFree:        28462460 kB
+Node 0 MemUsed:         4362672 kB
+Node 0 HugePages_Total:     0
+`,
+		"node0/numastat": `numa_hit 5238823
+numa_miss 12
+numa_foreign 34
+interleave_hit 1025
+local_node 5238800
+other_node 35
+`,
+		"node0/hugepages/hugepages-2048kB/nr_hugepages":      "512\n",
+		"node0/hugepages/hugepages-2048kB/free_hugepages":    "500\n",
+		"node0/hugepages/hugepages-2048kB/surplus_hugepages": "0\n",
+		"node1/cpulist":  "4-7,12-15\n",
+		"node1/distance": "21 10\n",
+		"node1/meminfo":  "Node 1 MemTotal:       33554432 kB\nNode 1 MemFree:        1024 kB\n",
+		"node2/cpulist":  "\n",
+	})
+
+	obtained := readNUMANodes(root)
+
+	c.Assert(len(obtained), Equals, 2)
+
+	c.Assert(obtained[0], DeepEquals, NUMANode{
+		ID:            0,
+		CPUs:          []int{0, 1, 2, 3, 8, 9, 10, 11},
+		MemTotal:      33612935168,
+		MemFree:       29145559040,
+		MemUsed:       4467376128,
+		NumaHit:       5238823,
+		NumaMiss:      12,
+		NumaForeign:   34,
+		InterleaveHit: 1025,
+		LocalNode:     5238800,
+		OtherNode:     35,
+		Distances:     []int{10, 21},
+		HugePages: []HugePagePool{
+			HugePagePool{PageSize: 2097152, Total: 512, Free: 500},
+		},
+	})
+
+	c.Assert(obtained[1].ID, Equals, 1)
+	c.Assert(obtained[1].CPUs, DeepEquals, []int{4, 5, 6, 7, 12, 13, 14, 15})
+	c.Assert(obtained[1].Distances, DeepEquals, []int{21, 10})
+	c.Assert(obtained[1].MemFree, Equals, uint64(1048576))
+	c.Assert(obtained[1].HugePages, IsNil)
+}
+
+func (s *NUMATestSuite) TestReadNUMANodes_NoOnlineFile(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"node0/cpulist":  "0\n",
+		"node2/cpulist":  "2\n",
+		"node10/cpulist": "10\n",
+		"possible":       "0,2,10\n",
+	})
+
+	obtained := readNUMANodes(root)
+
+	c.Assert(len(obtained), Equals, 3)
+	c.Assert(obtained[0].CPUs, DeepEquals, []int{0})
+	c.Assert(obtained[1].ID, Equals, 2)
+	c.Assert(obtained[2].ID, Equals, 10)
+}
+
+func (s *NUMATestSuite) TestParseCPUList(c *C) {
+	c.Assert(parseCPUList("0-3,8,10-11\n"), DeepEquals, []int{0, 1, 2, 3, 8, 10, 11})
+	c.Assert(parseCPUList("5"), DeepEquals, []int{5})
+	c.Assert(parseCPUList(""), IsNil)
+}
@@ -0,0 +1,646 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	PartitionTableMBR = "mbr"
+	PartitionTableGPT = "gpt"
+)
+
+// GPT partition attributes, bits 48 to 63 are defined by the partition type
+const (
+	GPTAttrRequired           = 1 << 0
+	GPTAttrNoBlockIOProtocol  = 1 << 1
+	GPTAttrLegacyBIOSBootable = 1 << 2
+)
+
+const (
+	procPartitionsPath = "/proc/partitions"
+
+	mbrSignature       = 0xaa55
+	gptSignature       = "EFI PART"
+	gptMaxEntries      = 4096
+	mbrMaxLogicalParts = 256
+)
+
+var (
+	ErrNoPartitionTable = &LibSysInfoErr{"No partition table found"}
+
+	// Sector sizes probed for a GPT header or an MBR, disk images are usually
+	// made of 512 bytes sectors but 4Kn disks use 4096 bytes ones
+	gptSectorSizes = []int64{512, 4096}
+
+	mbrTypeNames = map[uint8]string{
+		0x01: "FAT12",
+		0x04: "FAT16 <32M",
+		0x05: "Extended",
+		0x06: "FAT16",
+		0x07: "HPFS/NTFS/exFAT",
+		0x0b: "W95 FAT32",
+		0x0c: "W95 FAT32 (LBA)",
+		0x0e: "W95 FAT16 (LBA)",
+		0x0f: "W95 Ext'd (LBA)",
+		0x11: "Hidden FAT12",
+		0x17: "Hidden HPFS/NTFS",
+		0x1b: "Hidden W95 FAT32",
+		0x1c: "Hidden W95 FAT32 (LBA)",
+		0x27: "Hidden NTFS WinRE",
+		0x82: "Linux swap / Solaris",
+		0x83: "Linux",
+		0x85: "Linux extended",
+		0x8e: "Linux LVM",
+		0xa5: "FreeBSD",
+		0xa6: "OpenBSD",
+		0xa9: "NetBSD",
+		0xaf: "HFS / HFS+",
+		0xda: "Non-FS data",
+		0xee: "GPT",
+		0xef: "EFI (FAT-12/16/32)",
+		0xfb: "VMware VMFS",
+		0xfd: "Linux raid autodetect",
+	}
+
+	// Names used by fdisk, GUIDs are lower case
+	gptTypeNames = map[string]string{
+		"00000000-0000-0000-0000-000000000000": "Unused",
+		"c12a7328-f81f-11d2-ba4b-00a0c93ec93b": "EFI System",
+		"21686148-6449-6e6f-744e-656564454649": "BIOS boot",
+		"024dee41-33e7-11d3-9d69-0008c781f39f": "MBR partition scheme",
+		"e3c9e316-0b5c-4db8-817d-f92df00215ae": "Microsoft reserved",
+		"ebd0a0a2-b9e5-4433-87c0-68b6b72699c7": "Microsoft basic data",
+		"5808c8aa-7e8f-42e0-85d2-e1e90434cfb3": "Microsoft LDM metadata",
+		"af9b60a0-1431-4f62-bc68-3311714a69ad": "Microsoft LDM data",
+		"de94bba4-06d1-4d40-a16a-bfd50179d6ac": "Windows recovery environment",
+		"0fc63daf-8483-4772-8e79-3d69d8477de4": "Linux filesystem",
+		"0657fd6d-a4ab-43c4-84e5-0933c84b4f4f": "Linux swap",
+		"e6d6d379-f507-44c2-a23c-238f2a3df928": "Linux LVM",
+		"a19d880f-05fc-4d3b-a006-743f0f84911e": "Linux RAID",
+		"933ac7e1-2eb4-4f13-b844-0e14e2aef915": "Linux home",
+		"3b8f8425-20e0-4f3b-907f-1a25a76f98e8": "Linux server data",
+		"bc13c2ff-59e6-4262-a352-b275fd6f7172": "Linux extended boot",
+		"8da63339-0007-60c0-c436-083ac8230908": "Linux reserved",
+		"ca7d7ccb-63ed-4c53-861c-1742536059cc": "Linux LUKS",
+		"7ffec5c9-2d00-49b7-8941-3ea10a5586b7": "Linux plain dm-crypt",
+		"4d21b016-b534-45c2-a9fb-5c16e091fd2d": "Linux variable data",
+		"7ec6f557-3bc5-4aca-b293-16ef5df639d1": "Linux temporary data",
+		"44479540-f297-41b2-9af7-d131d5f0458a": "Linux root (x86)",
+		"4f68bce3-e8cd-4db1-96e7-fbcaf984b709": "Linux root (x86-64)",
+		"b921b045-1df0-41c3-af44-4c6f280d3fae": "Linux root (ARM-64)",
+		"8484680c-9521-48c6-9c11-b0720656f69e": "Linux /usr (x86-64)",
+		"b0e01050-ee5f-4390-949a-9101b17104e9": "Linux /usr (ARM-64)",
+		"48465300-0000-11aa-aa11-00306543ecac": "Apple HFS/HFS+",
+		"7c3457ef-0000-11aa-aa11-00306543ecac": "Apple APFS",
+		"516e7cb6-6ecf-11d6-8ff8-00022d09712b": "FreeBSD UFS",
+		"516e7cb5-6ecf-11d6-8ff8-00022d09712b": "FreeBSD swap",
+		"516e7cba-6ecf-11d6-8ff8-00022d09712b": "FreeBSD ZFS",
+		"6a898cc3-1dd2-11b2-99a6-080020736631": "Solaris /usr & Apple ZFS",
+		"fe3a2a5d-4f32-41a7-b725-accc3285a309": "ChromeOS kernel",
+		"aa31e02a-400f-11db-9590-000c2911d1b8": "VMware VMFS",
+	}
+)
+
+// ----
+
+// A partition table read from a disk image or a block device, or built from
+// /proc/partitions for live hosts
+type PartitionTable struct {
+	// Kernel name of the disk, e.g. sda. Empty for images.
+	Disk string
+
+	// One of the PartitionTable* constants. Empty when built from
+	// /proc/partitions, the kernel not exposing the table type.
+	Type string
+
+	// In bytes
+	Size       uint64
+	SectorSize int
+
+	// MBR disk signature, e.g. 5c1b0e6a, or the GPT disk GUID
+	ID string
+
+	// Only set for GPT disks
+	GPT *GPTHeader
+
+	Partitions []Partition
+}
+
+type GPTHeader struct {
+	Revision   uint32
+	HeaderSize uint32
+
+	// Set when the primary header is corrupted and the backup one was used
+	FromBackup bool
+
+	HeaderCRCValid  bool
+	EntriesCRCValid bool
+
+	CurrentLBA     uint64
+	BackupLBA      uint64
+	FirstUsableLBA uint64
+	LastUsableLBA  uint64
+
+	EntriesLBA uint64
+	NumEntries uint32
+	EntrySize  uint32
+}
+
+type Partition struct {
+	// Kernel name, e.g. sda1. Only set for live hosts.
+	Device string
+
+	// As numbered by the kernel, logical MBR partitions start at 5
+	Number int
+
+	// In bytes
+	Start uint64
+	Size  uint64
+
+	// MBR partition type, e.g. 0x83
+	MBRType  uint8
+	Bootable bool
+
+	// Set for logical partitions and for the extended partition holding
+	// them
+	Logical  bool
+	Extended bool
+
+	// GUIDs are lower case
+	TypeGUID   string
+	GUID       string
+	Name       string
+	Attributes uint64
+
+	// Resolved from TypeGUID or MBRType
+	TypeName string
+}
+
+// ----
+
+// Reads the partition table of a block device or of a disk image
+func Partitions(path string) (PartitionTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return PartitionTable{}, err
+	}
+	defer f.Close()
+
+	// stat reports a zero size for block devices
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return PartitionTable{}, err
+	}
+
+	return readPartitionTable(f, size, deviceSectorSizes(f))
+}
+
+// Decodes the GPT or MBR partition table found in r, size being the size of
+// the whole disk in bytes. A GPT is preferred over the protective or hybrid
+// MBR in front of it.
+func ReadPartitionTable(r io.ReaderAt, size int64) (PartitionTable, error) {
+	return readPartitionTable(r, size, gptSectorSizes)
+}
+
+// Returns the disks and partitions known to the kernel. Partition table
+// types and identifiers are left empty, use Partitions to read them.
+func KernelPartitions() ([]PartitionTable, error) {
+	buff, err := readFile(procPartitionsPath)
+	if err != nil {
+		return []PartitionTable(nil), err
+	}
+
+	return processProcPartitions(buff, sysBlockPath), nil
+}
+
+// ----
+
+// Returns the sector size the kernel uses for a block device, the sizes to
+// probe for a disk image
+func deviceSectorSizes(f *os.File) []int64 {
+	fi, err := f.Stat()
+	if err != nil || fi.Mode()&os.ModeDevice == 0 {
+		return gptSectorSizes
+	}
+
+	path, err := filepath.EvalSymlinks(f.Name())
+	if err != nil {
+		return gptSectorSizes
+	}
+
+	size := sysfsInt(filepath.Join(sysBlockPath, filepath.Base(path), "queue", "logical_block_size"))
+	if size <= 0 {
+		return gptSectorSizes
+	}
+
+	return []int64{int64(size)}
+}
+
+func readPartitionTable(r io.ReaderAt, size int64, sectorSizes []int64) (PartitionTable, error) {
+	for _, sectorSize := range sectorSizes {
+		pt, err := readGPT(r, size, sectorSize)
+		if err == nil {
+			return pt, nil
+		}
+		if err != ErrNoPartitionTable {
+			return PartitionTable{}, err
+		}
+	}
+
+	return readMBR(r, size, sectorSizes)
+}
+
+func readSector(r io.ReaderAt, offset int64, size int64) ([]byte, error) {
+	buff := make([]byte, size)
+
+	_, err := r.ReadAt(buff, offset)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, ErrNoPartitionTable
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buff, nil
+}
+
+func readMBR(r io.ReaderAt, size int64, sectorSizes []int64) (PartitionTable, error) {
+	// the MBR fills the first 512 bytes of LBA 0 whatever the sector size
+	sector, err := readSector(r, 0, 512)
+	if err != nil {
+		return PartitionTable{}, err
+	}
+
+	entries, ok := parseMBREntries(sector, 1)
+	if !ok {
+		return PartitionTable{}, ErrNoPartitionTable
+	}
+
+	sectorSize := mbrSectorSize(r, size, entries, sectorSizes)
+	for i := range entries {
+		entries[i].Start *= uint64(sectorSize)
+		entries[i].Size *= uint64(sectorSize)
+	}
+
+	pt := PartitionTable{
+		Type:       PartitionTableMBR,
+		Size:       uint64(size),
+		SectorSize: int(sectorSize),
+		ID:         fmt.Sprintf("%08x", binary.LittleEndian.Uint32(sector[440:])),
+	}
+
+	for i, e := range entries {
+		if e.MBRType == 0 {
+			continue
+		}
+
+		e.Number = i + 1
+		pt.Partitions = append(pt.Partitions, e)
+
+		if !e.Extended {
+			continue
+		}
+
+		logical, err := readMBRLogicalPartitions(r, e.Start, sectorSize)
+		if err != nil {
+			return PartitionTable{}, err
+		}
+
+		pt.Partitions = append(pt.Partitions, logical...)
+	}
+
+	return pt, nil
+}
+
+// Unlike a GPT, an MBR does not tell its sector size. Picks the first of
+// sectorSizes with which the partitions fit in the disk and the extended
+// partitions start with an EBR. Entries are given in sectors.
+func mbrSectorSize(r io.ReaderAt, size int64, entries []Partition, sectorSizes []int64) int64 {
+	for _, sectorSize := range sectorSizes {
+		if mbrFits(r, size, entries, sectorSize) {
+			return sectorSize
+		}
+	}
+
+	return sectorSizes[0]
+}
+
+func mbrFits(r io.ReaderAt, size int64, entries []Partition, sectorSize int64) bool {
+	for _, e := range entries {
+		if e.MBRType == 0 {
+			continue
+		}
+
+		if (e.Start+e.Size)*uint64(sectorSize) > uint64(size) {
+			return false
+		}
+
+		if !e.Extended {
+			continue
+		}
+
+		sector, err := readSector(r, int64(e.Start)*sectorSize, sectorSize)
+		if err != nil {
+			return false
+		}
+
+		_, ok := parseMBREntries(sector, sectorSize)
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Decodes the 4 entries of an MBR or of an EBR, with their start relative
+// to the sector holding them. Returns false when the sector holds no
+// partition table, e.g. a filesystem boot sector.
+func parseMBREntries(sector []byte, sectorSize int64) ([]Partition, bool) {
+	if binary.LittleEndian.Uint16(sector[510:]) != mbrSignature {
+		return nil, false
+	}
+
+	var entries []Partition
+
+	for i := 0; i < 4; i++ {
+		e := sector[446+i*16 : 446+(i+1)*16]
+
+		status := e[0]
+		if status != 0x00 && status != 0x80 {
+			return nil, false
+		}
+
+		p := Partition{
+			Start:    uint64(binary.LittleEndian.Uint32(e[8:])) * uint64(sectorSize),
+			Size:     uint64(binary.LittleEndian.Uint32(e[12:])) * uint64(sectorSize),
+			MBRType:  e[4],
+			Bootable: status == 0x80,
+			Extended: isMBRExtended(e[4]),
+			TypeName: mbrTypeNames[e[4]],
+		}
+		p.Logical = p.Extended
+
+		entries = append(entries, p)
+	}
+
+	return entries, true
+}
+
+func isMBRExtended(t uint8) bool {
+	return t == 0x05 || t == 0x0f || t == 0x85
+}
+
+// Follows the chain of extended boot records. The first entry of each EBR
+// describes a logical partition relative to the EBR, the second one the next
+// EBR relative to the extended partition.
+func readMBRLogicalPartitions(r io.ReaderAt, extStart uint64, sectorSize int64) ([]Partition, error) {
+	var partitions []Partition
+
+	ebr := extStart
+	for i := 0; i < mbrMaxLogicalParts; i++ {
+		sector, err := readSector(r, int64(ebr), sectorSize)
+		if err == ErrNoPartitionTable {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entries, ok := parseMBREntries(sector, sectorSize)
+		if !ok {
+			break
+		}
+
+		if entries[0].MBRType != 0 {
+			p := entries[0]
+			p.Number = 5 + len(partitions)
+			p.Start += ebr
+			p.Logical = true
+			partitions = append(partitions, p)
+		}
+
+		next := entries[1]
+		if !next.Extended || next.Start == 0 {
+			break
+		}
+
+		// links must move forward, which also prevents loops
+		if extStart+next.Start <= ebr {
+			break
+		}
+		ebr = extStart + next.Start
+	}
+
+	return partitions, nil
+}
+
+func readGPT(r io.ReaderAt, size int64, sectorSize int64) (PartitionTable, error) {
+	h, header, err := readGPTHeader(r, sectorSize, sectorSize)
+	if err != nil {
+		return PartitionTable{}, err
+	}
+
+	if !h.HeaderCRCValid {
+		lastLBA := size/sectorSize - 1
+		if h.BackupLBA > 0 && int64(h.BackupLBA) <= lastLBA {
+			lastLBA = int64(h.BackupLBA)
+		}
+
+		bh, backup, err := readGPTHeader(r, lastLBA*sectorSize, sectorSize)
+		if err == nil && bh.HeaderCRCValid {
+			bh.FromBackup = true
+			h, header = bh, backup
+		}
+	}
+
+	pt := PartitionTable{
+		Type:       PartitionTableGPT,
+		Size:       uint64(size),
+		SectorSize: int(sectorSize),
+		ID:         decodeGUID(header[56:72]),
+		GPT:        &h,
+	}
+
+	if h.NumEntries > gptMaxEntries || h.EntrySize < 128 || h.EntrySize > 4096 {
+		return pt, nil
+	}
+
+	entries, err := readSector(r, int64(h.EntriesLBA)*sectorSize, int64(h.NumEntries)*int64(h.EntrySize))
+	if err == ErrNoPartitionTable {
+		return pt, nil
+	}
+	if err != nil {
+		return PartitionTable{}, err
+	}
+
+	h.EntriesCRCValid = crc32.ChecksumIEEE(entries) == binary.LittleEndian.Uint32(header[88:])
+
+	for i := 0; i < int(h.NumEntries); i++ {
+		e := entries[i*int(h.EntrySize) : (i+1)*int(h.EntrySize)]
+
+		typeGUID := decodeGUID(e[0:16])
+		if typeGUID == "00000000-0000-0000-0000-000000000000" {
+			continue
+		}
+
+		firstLBA := binary.LittleEndian.Uint64(e[32:])
+		lastLBA := binary.LittleEndian.Uint64(e[40:])
+
+		p := Partition{
+			Number:     i + 1,
+			Start:      firstLBA * uint64(sectorSize),
+			TypeGUID:   typeGUID,
+			GUID:       decodeGUID(e[16:32]),
+			Attributes: binary.LittleEndian.Uint64(e[48:]),
+			Name:       decodeUTF16LE(e[56:128]),
+			TypeName:   gptTypeNames[typeGUID],
+		}
+
+		if lastLBA >= firstLBA {
+			p.Size = (lastLBA - firstLBA + 1) * uint64(sectorSize)
+		}
+
+		pt.Partitions = append(pt.Partitions, p)
+	}
+
+	return pt, nil
+}
+
+// Returns the decoded header and the raw one
+func readGPTHeader(r io.ReaderAt, offset int64, sectorSize int64) (GPTHeader, []byte, error) {
+	header, err := readSector(r, offset, sectorSize)
+	if err != nil {
+		return GPTHeader{}, nil, err
+	}
+
+	if string(header[0:8]) != gptSignature {
+		return GPTHeader{}, nil, ErrNoPartitionTable
+	}
+
+	h := GPTHeader{
+		Revision:       binary.LittleEndian.Uint32(header[8:]),
+		HeaderSize:     binary.LittleEndian.Uint32(header[12:]),
+		CurrentLBA:     binary.LittleEndian.Uint64(header[24:]),
+		BackupLBA:      binary.LittleEndian.Uint64(header[32:]),
+		FirstUsableLBA: binary.LittleEndian.Uint64(header[40:]),
+		LastUsableLBA:  binary.LittleEndian.Uint64(header[48:]),
+		EntriesLBA:     binary.LittleEndian.Uint64(header[72:]),
+		NumEntries:     binary.LittleEndian.Uint32(header[80:]),
+		EntrySize:      binary.LittleEndian.Uint32(header[84:]),
+	}
+
+	// the CRC covers the header with its CRC field zeroed
+	if h.HeaderSize >= 92 && int64(h.HeaderSize) <= sectorSize {
+		buff := make([]byte, h.HeaderSize)
+		copy(buff, header)
+		copy(buff[16:20], []byte{0, 0, 0, 0})
+
+		h.HeaderCRCValid = crc32.ChecksumIEEE(buff) == binary.LittleEndian.Uint32(header[16:])
+	}
+
+	return h, header, nil
+}
+
+// GUIDs are stored with their first three fields in little endian
+func decodeGUID(b []byte) string {
+	return fmt.Sprintf("%08x-%04x-%04x-%x-%x",
+		binary.LittleEndian.Uint32(b[0:4]),
+		binary.LittleEndian.Uint16(b[4:6]),
+		binary.LittleEndian.Uint16(b[6:8]),
+		b[8:10],
+		b[10:16],
+	)
+}
+
+// Decodes a NUL terminated UTF-16LE string
+func decodeUTF16LE(b []byte) string {
+	var u []uint16
+
+	for i := 0; i+1 < len(b); i += 2 {
+		c := binary.LittleEndian.Uint16(b[i:])
+		if c == 0 {
+			break
+		}
+
+		u = append(u, c)
+	}
+
+	return string(utf16.Decode(u))
+}
+
+// Parses /proc/partitions, e.g.
+//
+//	major minor  #blocks  name
+//
+//	   8        0  488386584 sda
+//	   8        1     524288 sda1
+func processProcPartitions(buff string, root string) []PartitionTable {
+	var tables []PartitionTable
+
+	index := make(map[string]int)
+
+	for _, line := range strings.Split(buff, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 4 || fields[0] == "major" {
+			continue
+		}
+
+		name := fields[3]
+		blocks, _ := strconv.ParseUint(fields[2], 10, 64)
+
+		disk := partitionDisk(root, name)
+		if disk == "" {
+			index[name] = len(tables)
+			tables = append(tables, PartitionTable{
+				Disk:       name,
+				Size:       blocks * 1024,
+				SectorSize: sysfsInt(filepath.Join(root, name, "queue", "logical_block_size")),
+			})
+
+			continue
+		}
+
+		i, exists := index[disk]
+		if !exists {
+			continue
+		}
+
+		dir := filepath.Join(root, disk, name)
+
+		tables[i].Partitions = append(tables[i].Partitions, Partition{
+			Device: name,
+			Number: sysfsInt(filepath.Join(dir, "partition")),
+			Start:  sysfsUint64(filepath.Join(dir, "start")) * sysfsSectorSize,
+			Size:   blocks * 1024,
+		})
+	}
+
+	return tables
+}
+
+// Returns the disk holding a partition, empty for whole disks
+func partitionDisk(root string, name string) string {
+	for _, disk := range dirNames(root) {
+		if disk == name || !strings.HasPrefix(name, disk) {
+			continue
+		}
+
+		_, err := os.Stat(filepath.Join(root, disk, name, "partition"))
+		if err == nil {
+			return disk
+		}
+	}
+
+	return ""
+}
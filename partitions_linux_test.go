@@ -0,0 +1,340 @@
+package libsysinfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"hash/crc32"
+	"strings"
+	"unicode/utf16"
+
+	. "launchpad.net/gocheck"
+)
+
+type PartitionsTestSuite struct{}
+
+var (
+	_ = Suite(&PartitionsTestSuite{})
+)
+
+// Writes an MBR style partition entry in sector at lba
+func writeMBREntry(img []byte, lba int, i int, status byte, typ byte, start uint32, sectors uint32) {
+	e := img[lba*512+446+i*16:]
+	e[0] = status
+	e[4] = typ
+	binary.LittleEndian.PutUint32(e[8:], start)
+	binary.LittleEndian.PutUint32(e[12:], sectors)
+
+	binary.LittleEndian.PutUint16(img[lba*512+510:], mbrSignature)
+}
+
+// Encodes a GUID the way GPT stores it, see decodeGUID
+func encodeGUID(c *C, guid string) []byte {
+	b, err := hex.DecodeString(strings.Replace(guid, "-", "", -1))
+	c.Assert(err, IsNil)
+	c.Assert(b, HasLen, 16)
+
+	b[0], b[1], b[2], b[3] = b[3], b[2], b[1], b[0]
+	b[4], b[5] = b[5], b[4]
+	b[6], b[7] = b[7], b[6]
+
+	return b
+}
+
+type gptFixtureEntry struct {
+	typeGUID string
+	guid     string
+	first    uint64
+	last     uint64
+	attrs    uint64
+	name     string
+}
+
+// Builds a GPT disk of the given number of sectors, with its primary and
+// backup headers and entries
+func makeGPTImage(c *C, sectorSize int, sectors int, entries []gptFixtureEntry) []byte {
+	img := make([]byte, sectorSize*sectors)
+
+	// protective MBR
+	writeMBREntry(img, 0, 0, 0x00, 0xee, 1, uint32(sectors-1))
+
+	const numEntries = 128
+	const entrySize = 128
+
+	table := make([]byte, numEntries*entrySize)
+	for i, e := range entries {
+		raw := table[i*entrySize:]
+		copy(raw[0:], encodeGUID(c, e.typeGUID))
+		copy(raw[16:], encodeGUID(c, e.guid))
+		binary.LittleEndian.PutUint64(raw[32:], e.first)
+		binary.LittleEndian.PutUint64(raw[40:], e.last)
+		binary.LittleEndian.PutUint64(raw[48:], e.attrs)
+
+		for j, u := range utf16.Encode([]rune(e.name)) {
+			binary.LittleEndian.PutUint16(raw[56+j*2:], u)
+		}
+	}
+
+	entriesSectors := numEntries * entrySize / sectorSize
+	lastLBA := uint64(sectors - 1)
+
+	writeHeader := func(current uint64, backup uint64, entriesLBA uint64) {
+		h := img[int(current)*sectorSize:]
+		copy(h, gptSignature)
+		binary.LittleEndian.PutUint32(h[8:], 0x00010000)
+		binary.LittleEndian.PutUint32(h[12:], 92)
+		binary.LittleEndian.PutUint64(h[24:], current)
+		binary.LittleEndian.PutUint64(h[32:], backup)
+		binary.LittleEndian.PutUint64(h[40:], uint64(2+entriesSectors))
+		binary.LittleEndian.PutUint64(h[48:], lastLBA-1-uint64(entriesSectors))
+		copy(h[56:], encodeGUID(c, "5e1c7ba9-1cf1-4b4a-9e28-7a6c1f1b0d42"))
+		binary.LittleEndian.PutUint64(h[72:], entriesLBA)
+		binary.LittleEndian.PutUint32(h[80:], numEntries)
+		binary.LittleEndian.PutUint32(h[84:], entrySize)
+		binary.LittleEndian.PutUint32(h[88:], crc32.ChecksumIEEE(table))
+		binary.LittleEndian.PutUint32(h[16:], crc32.ChecksumIEEE(h[:92]))
+
+		copy(img[int(entriesLBA)*sectorSize:], table)
+	}
+
+	writeHeader(1, lastLBA, 2)
+	writeHeader(lastLBA, 1, lastLBA-uint64(entriesSectors))
+
+	return img
+}
+
+func (s *PartitionsTestSuite) TestReadPartitionTable_GPT(c *C) {
+	img := makeGPTImage(c, 512, 2048, []gptFixtureEntry{
+		{
+			typeGUID: "c12a7328-f81f-11d2-ba4b-00a0c93ec93b",
+			guid:     "9a6f5d3e-2b1c-4e8f-a7d6-0c5b4a392817",
+			first:    34,
+			last:     1057,
+			attrs:    GPTAttrRequired,
+			name:     "EFI System Partition",
+		},
+		{
+			typeGUID: "4f68bce3-e8cd-4db1-96e7-fbcaf984b709",
+			guid:     "1b2c3d4e-5f60-4718-9a2b-3c4d5e6f7081",
+			first:    1058,
+			last:     2014,
+			attrs:    1 << 59,
+			name:     "root-x86-64",
+		},
+	})
+
+	pt, err := ReadPartitionTable(bytes.NewReader(img), int64(len(img)))
+	c.Assert(err, IsNil)
+
+	c.Check(pt.Type, Equals, PartitionTableGPT)
+	c.Check(pt.Size, Equals, uint64(2048*512))
+	c.Check(pt.SectorSize, Equals, 512)
+	c.Check(pt.ID, Equals, "5e1c7ba9-1cf1-4b4a-9e28-7a6c1f1b0d42")
+	c.Check(*pt.GPT, DeepEquals, GPTHeader{
+		Revision:        0x00010000,
+		HeaderSize:      92,
+		HeaderCRCValid:  true,
+		EntriesCRCValid: true,
+		CurrentLBA:      1,
+		BackupLBA:       2047,
+		FirstUsableLBA:  34,
+		LastUsableLBA:   2014,
+		EntriesLBA:      2,
+		NumEntries:      128,
+		EntrySize:       128,
+	})
+
+	c.Check(pt.Partitions, DeepEquals, []Partition{
+		{
+			Number:     1,
+			Start:      34 * 512,
+			Size:       1024 * 512,
+			TypeGUID:   "c12a7328-f81f-11d2-ba4b-00a0c93ec93b",
+			GUID:       "9a6f5d3e-2b1c-4e8f-a7d6-0c5b4a392817",
+			Name:       "EFI System Partition",
+			Attributes: GPTAttrRequired,
+			TypeName:   "EFI System",
+		},
+		{
+			Number:     2,
+			Start:      1058 * 512,
+			Size:       957 * 512,
+			TypeGUID:   "4f68bce3-e8cd-4db1-96e7-fbcaf984b709",
+			GUID:       "1b2c3d4e-5f60-4718-9a2b-3c4d5e6f7081",
+			Name:       "root-x86-64",
+			Attributes: 1 << 59,
+			TypeName:   "Linux root (x86-64)",
+		},
+	})
+
+	// a corrupted primary header falls back to the backup one
+	img[1*512+24] ^= 0xff
+
+	pt, err = ReadPartitionTable(bytes.NewReader(img), int64(len(img)))
+	c.Assert(err, IsNil)
+	c.Check(pt.GPT.FromBackup, Equals, true)
+	c.Check(pt.GPT.HeaderCRCValid, Equals, true)
+	c.Check(pt.GPT.EntriesCRCValid, Equals, true)
+	c.Check(pt.GPT.CurrentLBA, Equals, uint64(2047))
+	c.Check(pt.Partitions, HasLen, 2)
+
+	// both headers corrupted, entries are still decoded
+	img[2047*512+24] ^= 0xff
+	img[2*512+32] ^= 0xff
+
+	pt, err = ReadPartitionTable(bytes.NewReader(img), int64(len(img)))
+	c.Assert(err, IsNil)
+	c.Check(pt.GPT.FromBackup, Equals, false)
+	c.Check(pt.GPT.HeaderCRCValid, Equals, false)
+	c.Check(pt.GPT.EntriesCRCValid, Equals, false)
+	c.Check(pt.Partitions, HasLen, 2)
+}
+
+func (s *PartitionsTestSuite) TestReadPartitionTable_GPT4K(c *C) {
+	img := makeGPTImage(c, 4096, 256, []gptFixtureEntry{
+		{
+			typeGUID: "0fc63daf-8483-4772-8e79-3d69d8477de4",
+			guid:     "1b2c3d4e-5f60-4718-9a2b-3c4d5e6f7081",
+			first:    6,
+			last:     249,
+			name:     "data",
+		},
+	})
+
+	pt, err := ReadPartitionTable(bytes.NewReader(img), int64(len(img)))
+	c.Assert(err, IsNil)
+
+	c.Check(pt.Type, Equals, PartitionTableGPT)
+	c.Check(pt.SectorSize, Equals, 4096)
+	c.Check(pt.GPT.HeaderCRCValid, Equals, true)
+	c.Assert(pt.Partitions, HasLen, 1)
+	c.Check(pt.Partitions[0].Start, Equals, uint64(6*4096))
+	c.Check(pt.Partitions[0].Size, Equals, uint64(244*4096))
+	c.Check(pt.Partitions[0].TypeName, Equals, "Linux filesystem")
+}
+
+func (s *PartitionsTestSuite) TestReadPartitionTable_MBR(c *C) {
+	img := make([]byte, 4096*512)
+
+	binary.LittleEndian.PutUint32(img[440:], 0x5c1b0e6a)
+	writeMBREntry(img, 0, 0, 0x80, 0x83, 2048, 1024)
+	writeMBREntry(img, 0, 1, 0x00, 0x0f, 3072, 1024)
+
+	// first EBR: a logical partition and a link to the next EBR
+	writeMBREntry(img, 3072, 0, 0x00, 0x82, 64, 256)
+	writeMBREntry(img, 3072, 1, 0x00, 0x05, 512, 512)
+
+	// last EBR
+	writeMBREntry(img, 3584, 0, 0x00, 0x8e, 64, 256)
+
+	pt, err := ReadPartitionTable(bytes.NewReader(img), int64(len(img)))
+	c.Assert(err, IsNil)
+
+	c.Check(pt.Type, Equals, PartitionTableMBR)
+	c.Check(pt.ID, Equals, "5c1b0e6a")
+	c.Check(pt.SectorSize, Equals, 512)
+	c.Check(pt.GPT, IsNil)
+	c.Check(pt.Partitions, DeepEquals, []Partition{
+		{Number: 1, Start: 2048 * 512, Size: 1024 * 512, MBRType: 0x83, Bootable: true, TypeName: "Linux"},
+		{Number: 2, Start: 3072 * 512, Size: 1024 * 512, MBRType: 0x0f, Logical: true, Extended: true, TypeName: "W95 Ext'd (LBA)"},
+		{Number: 5, Start: 3136 * 512, Size: 256 * 512, MBRType: 0x82, Logical: true, TypeName: "Linux swap / Solaris"},
+		{Number: 6, Start: 3648 * 512, Size: 256 * 512, MBRType: 0x8e, Logical: true, TypeName: "Linux LVM"},
+	})
+}
+
+func (s *PartitionsTestSuite) TestReadPartitionTable_MBR4K(c *C) {
+	img := make([]byte, 512*4096)
+
+	// entries are written at lba*512, hence the 8x multiplier
+	writeMBREntry(img, 0, 0, 0x80, 0x83, 256, 128)
+	writeMBREntry(img, 0, 1, 0x00, 0x05, 384, 128)
+	writeMBREntry(img, 384*8, 0, 0x00, 0x82, 1, 32)
+
+	pt, err := ReadPartitionTable(bytes.NewReader(img), int64(len(img)))
+	c.Assert(err, IsNil)
+
+	c.Check(pt.Type, Equals, PartitionTableMBR)
+	c.Check(pt.SectorSize, Equals, 4096)
+	c.Check(pt.Partitions, DeepEquals, []Partition{
+		{Number: 1, Start: 256 * 4096, Size: 128 * 4096, MBRType: 0x83, Bootable: true, TypeName: "Linux"},
+		{Number: 2, Start: 384 * 4096, Size: 128 * 4096, MBRType: 0x05, Logical: true, Extended: true, TypeName: "Extended"},
+		{Number: 5, Start: 385 * 4096, Size: 32 * 4096, MBRType: 0x82, Logical: true, TypeName: "Linux swap / Solaris"},
+	})
+
+	// the sector size of block devices is known
+	pt, err = readPartitionTable(bytes.NewReader(img), int64(len(img)), []int64{512})
+	c.Assert(err, IsNil)
+	c.Check(pt.SectorSize, Equals, 512)
+	c.Check(pt.Partitions, HasLen, 2)
+	c.Check(pt.Partitions[0].Start, Equals, uint64(256*512))
+}
+
+func (s *PartitionsTestSuite) TestReadPartitionTable_None(c *C) {
+	img := make([]byte, 64*512)
+
+	_, err := ReadPartitionTable(bytes.NewReader(img), int64(len(img)))
+	c.Check(err, Equals, ErrNoPartitionTable)
+
+	// boot sector of a partitionless FAT filesystem
+	img[0] = 0xeb
+	binary.LittleEndian.PutUint16(img[510:], mbrSignature)
+	copy(img[446:], "FAT32   code and error messages")
+
+	_, err = ReadPartitionTable(bytes.NewReader(img), int64(len(img)))
+	c.Check(err, Equals, ErrNoPartitionTable)
+
+	// truncated image
+	_, err = ReadPartitionTable(bytes.NewReader(img[:100]), 100)
+	c.Check(err, Equals, ErrNoPartitionTable)
+}
+
+func (s *PartitionsTestSuite) TestProcessProcPartitions(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"sda/queue/logical_block_size":     "512\n",
+		"sda/sda1/partition":               "1\n",
+		"sda/sda1/start":                   "2048\n",
+		"sda/sda2/partition":               "2\n",
+		"sda/sda2/start":                   "1050624\n",
+		"nvme0n1/queue/logical_block_size": "4096\n",
+		"nvme0n1/nvme0n1p1/partition":      "1\n",
+		"nvme0n1/nvme0n1p1/start":          "2048\n",
+	})
+
+	buff := `major minor  #blocks  name
+
+   8        0  488386584 sda
+   8        1     524288 sda1
+   8        2  487861248 sda2
+ 259        0  976762584 nvme0n1
+ 259        1  976761560 nvme0n1p1
+ 253        0   41943040 dm-0
+`
+
+	tables := processProcPartitions(buff, root)
+
+	c.Check(tables, DeepEquals, []PartitionTable{
+		{
+			Disk:       "sda",
+			Size:       488386584 * 1024,
+			SectorSize: 512,
+			Partitions: []Partition{
+				{Device: "sda1", Number: 1, Start: 2048 * 512, Size: 524288 * 1024},
+				{Device: "sda2", Number: 2, Start: 1050624 * 512, Size: 487861248 * 1024},
+			},
+		},
+		{
+			Disk:       "nvme0n1",
+			Size:       976762584 * 1024,
+			SectorSize: 4096,
+			Partitions: []Partition{
+				{Device: "nvme0n1p1", Number: 1, Start: 2048 * 512, Size: 976761560 * 1024},
+			},
+		},
+		{
+			Disk: "dm-0",
+			Size: 41943040 * 1024,
+		},
+	})
+}
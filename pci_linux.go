@@ -0,0 +1,233 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	sysPCIDevicesPath = "/sys/bus/pci/devices"
+	sysClassNetPath   = "/sys/class/net"
+)
+
+var (
+	// Locations searched for pci.ids, in order. The embedded subset is used
+	// when none exists.
+	PCIIDsPaths = []string{
+		"/usr/share/hwdata/pci.ids",
+		"/usr/share/misc/pci.ids",
+		"/usr/share/pci.ids",
+		"/usr/local/share/pci.ids",
+	}
+
+	ErrNotPCIDevice = &LibSysInfoErr{"Not a PCI device"}
+
+	pciIDsCache = newIDDatabases()
+)
+
+// ----
+
+type PCIDevice struct {
+	// e.g. 0000:3b:00.0
+	Address string
+
+	Class    uint8
+	Subclass uint8
+	ProgIF   uint8
+
+	VendorID          uint16
+	DeviceID          uint16
+	SubsystemVendorID uint16
+	SubsystemDeviceID uint16
+	Revision          uint8
+
+	// Resolved from pci.ids, empty when unknown
+	ClassName     string
+	SubclassName  string
+	ProgIFName    string
+	VendorName    string
+	DeviceName    string
+	SubsystemName string
+
+	// Empty when no driver is bound
+	Driver string
+
+	// -1 when the IOMMU is disabled
+	IOMMUGroup int
+
+	// -1 on systems without NUMA
+	NUMANode int
+
+	// PCI Express link, e.g. "16.0 GT/s PCIe". Empty for conventional PCI
+	// devices.
+	LinkSpeed    string
+	LinkWidth    int
+	MaxLinkSpeed string
+	MaxLinkWidth int
+
+	// Virtual functions of SR-IOV capable devices
+	SRIOVTotalVFs int
+	SRIOVNumVFs   int
+
+	// Network interfaces backed by the device
+	NetworkInterfaces []string
+}
+
+// ----
+
+// Returns the PCI devices, with their identifiers resolved using the first
+// pci.ids found in PCIIDsPaths
+func PCIDevices() ([]PCIDevice, error) {
+	_, err := os.Stat(sysPCIDevicesPath)
+	if err != nil {
+		return []PCIDevice(nil), err
+	}
+
+	db, err := PCIIDs()
+	if err != nil {
+		return []PCIDevice(nil), err
+	}
+
+	return readPCIDevices(sysPCIDevicesPath, db), nil
+}
+
+// Loads the first pci.ids found in PCIIDsPaths, or the embedded subset. Each
+// file is parsed once and shared by the following lookups
+func PCIIDs() (*IDDatabase, error) {
+	return pciIDsCache.load(PCIIDsPaths, pciIDsFallback)
+}
+
+// Returns the PCI device backing the interface, ErrNotPCIDevice for virtual
+// interfaces or interfaces on other buses
+func (nif NetworkInterface) PCIDevice() (PCIDevice, error) {
+	address, err := pciAddressOf(filepath.Join(sysClassNetPath, nif.Name, "device"))
+	if err != nil {
+		return PCIDevice{}, err
+	}
+
+	db, err := PCIIDs()
+	if err != nil {
+		return PCIDevice{}, err
+	}
+
+	return readPCIDevice(sysPCIDevicesPath, address, db), nil
+}
+
+// ----
+
+func readPCIDevices(root string, db *IDDatabase) []PCIDevice {
+	var devices []PCIDevice
+
+	for _, address := range dirNames(root) {
+		devices = append(devices, readPCIDevice(root, address, db))
+	}
+
+	return devices
+}
+
+func readPCIDevice(root string, address string, db *IDDatabase) PCIDevice {
+	dir := filepath.Join(root, address)
+
+	class := sysfsHex(filepath.Join(dir, "class"))
+
+	d := PCIDevice{
+		Address:           address,
+		Class:             uint8(class >> 16),
+		Subclass:          uint8(class >> 8),
+		ProgIF:            uint8(class),
+		VendorID:          uint16(sysfsHex(filepath.Join(dir, "vendor"))),
+		DeviceID:          uint16(sysfsHex(filepath.Join(dir, "device"))),
+		SubsystemVendorID: uint16(sysfsHex(filepath.Join(dir, "subsystem_vendor"))),
+		SubsystemDeviceID: uint16(sysfsHex(filepath.Join(dir, "subsystem_device"))),
+		Revision:          uint8(sysfsHex(filepath.Join(dir, "revision"))),
+		Driver:            linkBase(filepath.Join(dir, "driver")),
+		IOMMUGroup:        -1,
+		NUMANode:          -1,
+		LinkSpeed:         sysfsString(filepath.Join(dir, "current_link_speed")),
+		LinkWidth:         sysfsInt(filepath.Join(dir, "current_link_width")),
+		MaxLinkSpeed:      sysfsString(filepath.Join(dir, "max_link_speed")),
+		MaxLinkWidth:      sysfsInt(filepath.Join(dir, "max_link_width")),
+		SRIOVTotalVFs:     sysfsInt(filepath.Join(dir, "sriov_totalvfs")),
+		SRIOVNumVFs:       sysfsInt(filepath.Join(dir, "sriov_numvfs")),
+		NetworkInterfaces: pciNetworkInterfaces(dir),
+	}
+
+	group, err := strconv.Atoi(linkBase(filepath.Join(dir, "iommu_group")))
+	if err == nil {
+		d.IOMMUGroup = group
+	}
+
+	node, err := strconv.Atoi(sysfsString(filepath.Join(dir, "numa_node")))
+	if err == nil {
+		d.NUMANode = node
+	}
+
+	// "Unknown" is reported by devices without a PCIe capability
+	if strings.HasPrefix(d.LinkSpeed, "Unknown") {
+		d.LinkSpeed = ""
+	}
+	if strings.HasPrefix(d.MaxLinkSpeed, "Unknown") {
+		d.MaxLinkSpeed = ""
+	}
+
+	if db != nil {
+		d.ClassName = db.ClassName(d.Class)
+		d.SubclassName = db.SubclassName(d.Class, d.Subclass)
+		d.ProgIFName = db.ProgIFName(d.Class, d.Subclass, d.ProgIF)
+		d.VendorName = db.VendorName(d.VendorID)
+		d.DeviceName = db.DeviceName(d.VendorID, d.DeviceID)
+		d.SubsystemName = db.SubsystemName(d.VendorID, d.DeviceID, d.SubsystemVendorID, d.SubsystemDeviceID)
+	}
+
+	return d
+}
+
+// Returns the PCI address of a sysfs device or of its closest PCI parent,
+// e.g. the PCI function of a virtio device
+func pciAddressOf(devicePath string) (string, error) {
+	dir, err := filepath.EvalSymlinks(devicePath)
+	if os.IsNotExist(err) {
+		return "", ErrNotPCIDevice
+	}
+	if err != nil {
+		return "", err
+	}
+
+	for dir != filepath.Dir(dir) {
+		if linkBase(filepath.Join(dir, "subsystem")) == "pci" {
+			return filepath.Base(dir), nil
+		}
+
+		dir = filepath.Dir(dir)
+	}
+
+	return "", ErrNotPCIDevice
+}
+
+// Returns the network interfaces of a PCI device, including the ones of its
+// non PCI child devices such as virtio0. The functions behind a bridge are
+// PCI devices of their own and are skipped
+func pciNetworkInterfaces(dir string) []string {
+	var names []string
+
+	paths, _ := filepath.Glob(filepath.Join(dir, "net", "*"))
+	for _, path := range paths {
+		names = append(names, filepath.Base(path))
+	}
+
+	paths, _ = filepath.Glob(filepath.Join(dir, "*", "net", "*"))
+	for _, path := range paths {
+		child := filepath.Dir(filepath.Dir(path))
+		if linkBase(filepath.Join(child, "subsystem")) == "pci" {
+			continue
+		}
+
+		names = append(names, filepath.Base(path))
+	}
+
+	return names
+}
@@ -0,0 +1,209 @@
+package libsysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "launchpad.net/gocheck"
+)
+
+type PCITestSuite struct{}
+
+var (
+	_ = Suite(&PCITestSuite{})
+)
+
+func (s *PCITestSuite) TestReadPCIDevices(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"devices/0000:00:00.0/class":              "0x060000\n",
+		"devices/0000:00:00.0/vendor":             "0x8086\n",
+		"devices/0000:00:00.0/device":             "0x1237\n",
+		"devices/0000:00:00.0/subsystem_vendor":   "0x1af4\n",
+		"devices/0000:00:00.0/subsystem_device":   "0x1100\n",
+		"devices/0000:00:00.0/revision":           "0x02\n",
+		"devices/0000:00:00.0/numa_node":          "-1\n",
+		"devices/0000:3b:00.0/class":              "0x020000\n",
+		"devices/0000:3b:00.0/vendor":             "0x15b3\n",
+		"devices/0000:3b:00.0/device":             "0x1017\n",
+		"devices/0000:3b:00.0/subsystem_vendor":   "0x15b3\n",
+		"devices/0000:3b:00.0/subsystem_device":   "0x0007\n",
+		"devices/0000:3b:00.0/revision":           "0x00\n",
+		"devices/0000:3b:00.0/numa_node":          "0\n",
+		"devices/0000:3b:00.0/current_link_speed": "8.0 GT/s PCIe\n",
+		"devices/0000:3b:00.0/current_link_width": "16\n",
+		"devices/0000:3b:00.0/max_link_speed":     "8.0 GT/s PCIe\n",
+		"devices/0000:3b:00.0/max_link_width":     "16\n",
+		"devices/0000:3b:00.0/sriov_totalvfs":     "8\n",
+		"devices/0000:3b:00.0/sriov_numvfs":       "2\n",
+		"devices/0000:3b:00.0/net/ens1f0/uevent":  "",
+		"drivers/mlx5_core/uevent":                "",
+		"iommu_groups/42/uevent":                  "",
+	})
+
+	links := map[string]string{
+		"devices/0000:3b:00.0/driver":      "../../drivers/mlx5_core",
+		"devices/0000:3b:00.0/iommu_group": "../../iommu_groups/42",
+	}
+	for name, target := range links {
+		c.Assert(os.Symlink(target, filepath.Join(root, name)), IsNil)
+	}
+
+	db, err := ParseIDDatabase(strings.NewReader(pciIDsFallback))
+	c.Assert(err, IsNil)
+
+	obtained := readPCIDevices(filepath.Join(root, "devices"), db)
+
+	expected := []PCIDevice{
+		PCIDevice{
+			Address:           "0000:00:00.0",
+			Class:             0x06,
+			VendorID:          0x8086,
+			DeviceID:          0x1237,
+			SubsystemVendorID: 0x1af4,
+			SubsystemDeviceID: 0x1100,
+			Revision:          0x02,
+			ClassName:         "Bridge",
+			SubclassName:      "Host bridge",
+			VendorName:        "Intel Corporation",
+			DeviceName:        "440FX - 82441FX PMC [Natoma]",
+			IOMMUGroup:        -1,
+			NUMANode:          -1,
+		},
+		PCIDevice{
+			Address:           "0000:3b:00.0",
+			Class:             0x02,
+			VendorID:          0x15b3,
+			DeviceID:          0x1017,
+			SubsystemVendorID: 0x15b3,
+			SubsystemDeviceID: 0x0007,
+			ClassName:         "Network controller",
+			SubclassName:      "Ethernet controller",
+			VendorName:        "Mellanox Technologies",
+			DeviceName:        "MT27800 Family [ConnectX-5]",
+			Driver:            "mlx5_core",
+			IOMMUGroup:        42,
+			NUMANode:          0,
+			LinkSpeed:         "8.0 GT/s PCIe",
+			LinkWidth:         16,
+			MaxLinkSpeed:      "8.0 GT/s PCIe",
+			MaxLinkWidth:      16,
+			SRIOVTotalVFs:     8,
+			SRIOVNumVFs:       2,
+			NetworkInterfaces: []string{"ens1f0"},
+		},
+	}
+
+	c.Assert(obtained, DeepEquals, expected)
+}
+
+func (s *PCITestSuite) TestPCIIDs_Fallback(c *C) {
+	saved := PCIIDsPaths
+	defer func() { PCIIDsPaths = saved }()
+
+	PCIIDsPaths = []string{filepath.Join(c.MkDir(), "missing")}
+
+	db, err := PCIIDs()
+	c.Assert(err, IsNil)
+	c.Assert(db.DeviceName(0x1af4, 0x1041), Equals, "Virtio 1.0 network device")
+	c.Assert(db.ProgIFName(0x01, 0x08, 0x02), Equals, "NVM Express")
+}
+
+func (s *PCITestSuite) TestPCIIDs_Paths(c *C) {
+	saved := PCIIDsPaths
+	defer func() { PCIIDsPaths = saved }()
+
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"a/pci.ids": "8086  Intel Corporation\n",
+		"b/pci.ids": "8086  Intel Corp.\n",
+	})
+
+	PCIIDsPaths = []string{filepath.Join(root, "a/pci.ids")}
+
+	db, err := PCIIDs()
+	c.Assert(err, IsNil)
+	c.Assert(db.VendorName(0x8086), Equals, "Intel Corporation")
+
+	again, err := PCIIDs()
+	c.Assert(err, IsNil)
+	c.Assert(again == db, Equals, true)
+
+	PCIIDsPaths = []string{filepath.Join(root, "b/pci.ids")}
+
+	db, err = PCIIDs()
+	c.Assert(err, IsNil)
+	c.Assert(db.VendorName(0x8086), Equals, "Intel Corp.")
+
+	// unreadable files are retried on the next lookup
+	PCIIDsPaths = []string{filepath.Join(root, "a")}
+
+	_, err = PCIIDs()
+	c.Assert(err, NotNil)
+	_, present := pciIDsCache.get(filepath.Join(root, "a"))
+	c.Assert(present, Equals, false)
+}
+
+func (s *PCITestSuite) TestPCIAddressOf(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"devices/pci0000:00/0000:00:04.0/uevent":         "",
+		"bus/pci/uevent":                                 "",
+		"devices/pci0000:00/0000:00:04.0/virtio1/uevent": "",
+		"net/eth0/uevent":                                "",
+		"net/eth1/uevent":                                "",
+		"net/lo/uevent":                                  "",
+	})
+
+	c.Assert(os.Symlink("../../bus/pci", filepath.Join(root, "devices/pci0000:00/0000:00:04.0/subsystem")), IsNil)
+	c.Assert(os.Symlink("../../devices/pci0000:00/0000:00:04.0", filepath.Join(root, "net/eth0/device")), IsNil)
+
+	c.Assert(os.Symlink("../../devices/pci0000:00/0000:00:04.0/virtio1", filepath.Join(root, "net/eth1/device")), IsNil)
+
+	address, err := pciAddressOf(filepath.Join(root, "net/eth0/device"))
+	c.Assert(err, IsNil)
+	c.Assert(address, Equals, "0000:00:04.0")
+
+	address, err = pciAddressOf(filepath.Join(root, "net/eth1/device"))
+	c.Assert(err, IsNil)
+	c.Assert(address, Equals, "0000:00:04.0")
+
+	_, err = pciAddressOf(filepath.Join(root, "net/lo/device"))
+	c.Assert(err, Equals, ErrNotPCIDevice)
+}
+
+func (s *PCITestSuite) TestPCINetworkInterfaces(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"bus/pci/uevent":    "",
+		"bus/virtio/uevent": "",
+		"devices/pci0000:00/0000:00:1c.0/pci_bus/0000:02/uevent":         "",
+		"devices/pci0000:00/0000:00:1c.0/0000:02:00.0/net/enp2s0/uevent": "",
+		"devices/pci0000:00/0000:00:04.0/virtio0/net/eth0/uevent":        "",
+	})
+
+	links := map[string]string{
+		"devices/pci0000:00/0000:00:1c.0/subsystem":              "../../../bus/pci",
+		"devices/pci0000:00/0000:00:1c.0/0000:02:00.0/subsystem": "../../../../bus/pci",
+		"devices/pci0000:00/0000:00:04.0/subsystem":              "../../../bus/pci",
+		"devices/pci0000:00/0000:00:04.0/virtio0/subsystem":      "../../../../bus/virtio",
+	}
+	for name, target := range links {
+		c.Assert(os.Symlink(target, filepath.Join(root, name)), IsNil)
+	}
+
+	// the interface belongs to the endpoint, not to the root port above it
+	obtained := pciNetworkInterfaces(filepath.Join(root, "devices/pci0000:00/0000:00:1c.0"))
+	c.Assert(obtained, IsNil)
+
+	obtained = pciNetworkInterfaces(filepath.Join(root, "devices/pci0000:00/0000:00:1c.0/0000:02:00.0"))
+	c.Assert(obtained, DeepEquals, []string{"enp2s0"})
+
+	obtained = pciNetworkInterfaces(filepath.Join(root, "devices/pci0000:00/0000:00:04.0"))
+	c.Assert(obtained, DeepEquals, []string{"eth0"})
+}
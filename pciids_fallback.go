@@ -0,0 +1,223 @@
+package libsysinfo
+
+// Subset of pci.ids used when no database is installed. It holds every
+// device class and the vendors and devices most commonly found on servers
+// and virtual machines.
+const pciIDsFallback = `
+1000  Broadcom / LSI
+1002  Advanced Micro Devices, Inc. [AMD/ATI]
+1022  Advanced Micro Devices, Inc. [AMD]
+1077  QLogic Corp.
+10de  NVIDIA Corporation
+10ec  Realtek Semiconductor Co., Ltd.
+	8168  RTL8111/8168/8211/8411 PCI Express Gigabit Ethernet Controller
+1234  Technical Corp.
+	1111  QEMU Virtual Video Controller
+13fe  Advantech Co. Ltd
+1414  Microsoft Corporation
+144d  Samsung Electronics Co Ltd
+14e4  Broadcom Inc. and subsidiaries
+15ad  VMware
+	0405  SVGA II Adapter
+	0740  Virtual Machine Communication Interface
+	07b0  VMXNET3 Ethernet Controller
+	07c0  PVSCSI SCSI Controller
+15b3  Mellanox Technologies
+	1017  MT27800 Family [ConnectX-5]
+	101b  MT28908 Family [ConnectX-6]
+1ae0  Google, Inc.
+	0042  Compute Engine Virtual Ethernet [gVNIC]
+1af4  Red Hat, Inc.
+	1000  Virtio network device
+	1001  Virtio block device
+	1002  Virtio memory balloon
+	1003  Virtio console
+	1004  Virtio SCSI
+	1005  Virtio RNG
+	1009  Virtio filesystem
+	1041  Virtio 1.0 network device
+	1042  Virtio 1.0 block device
+	1043  Virtio 1.0 console
+	1044  Virtio 1.0 RNG
+	1045  Virtio 1.0 balloon
+	1048  Virtio 1.0 SCSI
+	1049  Virtio 1.0 filesystem
+	1050  Virtio 1.0 GPU
+	1052  Virtio 1.0 input
+	1053  Virtio 1.0 socket
+1b36  Red Hat, Inc.
+	0001  QEMU PCI-PCI bridge
+	000d  QEMU XHCI Host Controller
+	0010  QEMU NVM Express Controller
+1b4b  Marvell Technology Group Ltd.
+1d0f  Amazon.com, Inc.
+	8061  NVMe EBS Controller
+	ec20  Elastic Network Adapter (ENA)
+5853  XenSource, Inc.
+	0001  Xen Platform Device
+8086  Intel Corporation
+	100e  82540EM Gigabit Ethernet Controller
+	10d3  82574L Gigabit Network Connection
+	1237  440FX - 82441FX PMC [Natoma]
+	1521  I350 Gigabit Network Connection
+	1572  Ethernet Controller X710 for 10GbE SFP+
+	2918  82801IB (ICH9) LPC Interface Controller
+	2922  82801IR/IO/IH (ICH9R/DO/DH) 6 port SATA Controller [AHCI mode]
+	29c0  82G33/G31/P35/P31 Express DRAM Controller
+	7000  82371SB PIIX3 ISA [Natoma/Triton II]
+	7010  82371SB PIIX3 IDE [Natoma/Triton II]
+	7113  82371AB/EB/MB PIIX4 ACPI
+C 00  Unclassified device
+	00  Non-VGA unclassified device
+	01  VGA compatible unclassified device
+	05  Image coprocessor
+C 01  Mass storage controller
+	00  SCSI storage controller
+	01  IDE interface
+	02  Floppy disk controller
+	03  IPI bus controller
+	04  RAID bus controller
+	05  ATA controller
+	06  SATA controller
+		00  Vendor specific
+		01  AHCI 1.0
+		02  Serial Storage Bus
+	07  Serial Attached SCSI controller
+		01  Serial Storage Bus
+	08  Non-Volatile memory controller
+		01  NVMHCI
+		02  NVM Express
+	09  Universal Flash Storage controller
+	80  Mass storage controller
+C 02  Network controller
+	00  Ethernet controller
+	01  Token ring network controller
+	02  FDDI network controller
+	03  ATM network controller
+	04  ISDN controller
+	05  WorldFip controller
+	06  PICMG controller
+	07  Infiniband controller
+	08  Fabric controller
+	80  Network controller
+C 03  Display controller
+	00  VGA compatible controller
+		00  VGA controller
+		01  8514 controller
+	01  XGA compatible controller
+	02  3D controller
+	80  Display controller
+C 04  Multimedia controller
+	00  Multimedia video controller
+	01  Multimedia audio controller
+	02  Computer telephony device
+	03  Audio device
+	80  Multimedia controller
+C 05  Memory controller
+	00  RAM memory
+	01  FLASH memory
+	02  CXL
+	80  Memory controller
+C 06  Bridge
+	00  Host bridge
+	01  ISA bridge
+	02  EISA bridge
+	03  MicroChannel bridge
+	04  PCI bridge
+		00  Normal decode
+		01  Subtractive decode
+	05  PCMCIA bridge
+	06  NuBus bridge
+	07  CardBus bridge
+	08  RACEway bridge
+	09  Semi-transparent PCI-to-PCI bridge
+	0a  InfiniBand to PCI host bridge
+	80  Bridge
+C 07  Communication controller
+	00  Serial controller
+	01  Parallel controller
+	02  Multiport serial controller
+	03  Modem
+	04  GPIB controller
+	05  Smard Card controller
+	80  Communication controller
+C 08  Generic system peripheral
+	00  PIC
+	01  DMA controller
+	02  Timer
+	03  RTC
+	04  PCI Hot-plug controller
+	05  SD Host controller
+	06  IOMMU
+	80  System peripheral
+	99  Timing Card
+C 09  Input device controller
+	00  Keyboard controller
+	01  Digitizer Pen
+	02  Mouse controller
+	03  Scanner controller
+	04  Gameport controller
+	80  Input device controller
+C 0a  Docking station
+	00  Generic Docking Station
+	80  Docking Station
+C 0b  Processor
+	00  386
+	01  486
+	02  Pentium
+	10  Alpha
+	20  Power PC
+	30  MIPS
+	40  Co-processor
+C 0c  Serial bus controller
+	00  FireWire (IEEE 1394)
+	01  ACCESS Bus
+	02  SSA
+	03  USB controller
+		00  UHCI
+		10  OHCI
+		20  EHCI
+		30  XHCI
+		40  USB4 Host Interface
+		80  Unspecified
+		fe  USB Device
+	04  Fibre Channel
+	05  SMBus
+	06  InfiniBand
+	07  IPMI Interface
+	08  SERCOS interface
+	09  CANBUS
+	80  Serial bus controller
+C 0d  Wireless controller
+	00  IRDA controller
+	01  Consumer IR controller
+	10  RF controller
+	11  Bluetooth
+	12  Broadband
+	20  802.1a controller
+	21  802.1b controller
+	80  Wireless controller
+C 0e  Intelligent controller
+	00  I2O
+C 0f  Satellite communications controller
+	01  Satellite TV controller
+	02  Satellite audio communication controller
+	03  Satellite voice communication controller
+	04  Satellite data communication controller
+C 10  Encryption controller
+	00  Network and computing encryption device
+	10  Entertainment encryption device
+	80  Encryption controller
+C 11  Signal processing controller
+	00  DPIO module
+	01  Performance counters
+	10  Communication synchronizer
+	20  Signal processing management
+	80  Signal processing controller
+C 12  Processing accelerators
+	00  Processing accelerators
+	01  SNIA Smart Data Accelerator Interface (SDXI) controller
+C 13  Non-Essential Instrumentation
+C 40  Coprocessor
+C ff  Unassigned class
+`
@@ -0,0 +1,227 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	PowerSupplyMains    = "Mains"
+	PowerSupplyBattery  = "Battery"
+	PowerSupplyUPS      = "UPS"
+	PowerSupplyUSB      = "USB"
+	PowerSupplyWireless = "Wireless"
+)
+
+const (
+	BatteryCharging    = "Charging"
+	BatteryDischarging = "Discharging"
+	BatteryNotCharging = "Not charging"
+	BatteryFull        = "Full"
+	BatteryUnknown     = "Unknown"
+)
+
+const (
+	sysPowerSupplyPath = "/sys/class/power_supply"
+
+	// sysfs reports micro units
+	powerSupplyScale = 1000000
+)
+
+// ----
+
+// A power source or battery. Values are in SI units: Wh for energies, Ah
+// for charges, V, A and W. Values the driver does not report are zero.
+// Batteries report either energies or charges.
+type PowerSupply struct {
+	// e.g. AC, BAT0 or ucsi-source-psy-USBC000:001
+	Name string
+
+	// One of the PowerSupply* constants
+	Type string
+
+	// Whether a mains or USB supply is plugged in
+	Online bool
+
+	// Whether a battery is present in its bay
+	Present bool
+
+	// One of the Battery* constants
+	Status string
+
+	// Remaining capacity in percent, -1 when not reported
+	Capacity int
+
+	// e.g. Normal, Low or Critical
+	CapacityLevel string
+
+	EnergyNow        float64
+	EnergyFull       float64
+	EnergyFullDesign float64
+
+	ChargeNow        float64
+	ChargeFull       float64
+	ChargeFullDesign float64
+
+	Voltage          float64
+	VoltageMinDesign float64
+
+	// Some drivers report a negative current while discharging
+	Current float64
+	Power   float64
+
+	CycleCount int
+
+	// e.g. Li-ion or Li-poly
+	Technology   string
+	Manufacturer string
+	ModelName    string
+	SerialNumber string
+
+	// Estimates computed by the driver, zero when not provided. See
+	// TimeToEmpty() and TimeToFull() for ones falling back on the readings
+	TimeToEmptyNow time.Duration
+	TimeToFullNow  time.Duration
+}
+
+// ----
+
+func PowerSupplies() ([]PowerSupply, error) {
+	_, err := os.Stat(sysPowerSupplyPath)
+	if err != nil {
+		return []PowerSupply(nil), err
+	}
+
+	return readPowerSupplies(sysPowerSupplyPath), nil
+}
+
+// Returns the full capacity of a battery relative to its design capacity,
+// in percent. Zero when the battery does not report them.
+func (ps PowerSupply) Health() float64 {
+	if ps.EnergyFullDesign > 0 {
+		return 100 * ps.EnergyFull / ps.EnergyFullDesign
+	}
+
+	if ps.ChargeFullDesign > 0 {
+		return 100 * ps.ChargeFull / ps.ChargeFullDesign
+	}
+
+	return 0
+}
+
+// Returns the power drawn from or fed to a battery in W, computed from the
+// current and voltage when the driver does not report it
+func (ps PowerSupply) PowerDraw() float64 {
+	if ps.Power != 0 {
+		return math.Abs(ps.Power)
+	}
+
+	return math.Abs(ps.Current * ps.Voltage)
+}
+
+// Estimates the time left before a discharging battery is empty, zero when
+// not discharging or when it can not be estimated
+func (ps PowerSupply) TimeToEmpty() time.Duration {
+	if ps.Status != BatteryDischarging {
+		return 0
+	}
+
+	if ps.TimeToEmptyNow > 0 {
+		return ps.TimeToEmptyNow
+	}
+
+	return powerSupplyEstimate(ps.EnergyNow, ps.PowerDraw(), ps.ChargeNow, math.Abs(ps.Current))
+}
+
+// Estimates the time left before a charging battery is full, zero when not
+// charging or when it can not be estimated
+func (ps PowerSupply) TimeToFull() time.Duration {
+	if ps.Status != BatteryCharging {
+		return 0
+	}
+
+	if ps.TimeToFullNow > 0 {
+		return ps.TimeToFullNow
+	}
+
+	return powerSupplyEstimate(
+		ps.EnergyFull-ps.EnergyNow, ps.PowerDraw(),
+		ps.ChargeFull-ps.ChargeNow, math.Abs(ps.Current),
+	)
+}
+
+// ----
+
+// Returns the time needed to move energy (Wh) at power (W), or charge (Ah)
+// at current (A)
+func powerSupplyEstimate(energy float64, power float64, charge float64, current float64) time.Duration {
+	var hours float64
+
+	switch {
+	case energy > 0 && power > 0:
+		hours = energy / power
+	case charge > 0 && current > 0:
+		hours = charge / current
+	default:
+		return 0
+	}
+
+	return time.Duration(hours * float64(time.Hour))
+}
+
+func readPowerSupplies(root string) []PowerSupply {
+	var supplies []PowerSupply
+
+	for _, name := range dirNames(root) {
+		supplies = append(supplies, readPowerSupply(root, name))
+	}
+
+	return supplies
+}
+
+func readPowerSupply(root string, name string) PowerSupply {
+	dir := filepath.Join(root, name)
+
+	micro := func(attr string) float64 {
+		return float64(sysfsInt(filepath.Join(dir, attr))) / powerSupplyScale
+	}
+
+	ps := PowerSupply{
+		Name:             name,
+		Type:             sysfsString(filepath.Join(dir, "type")),
+		Online:           sysfsBool(filepath.Join(dir, "online")),
+		Present:          sysfsBool(filepath.Join(dir, "present")),
+		Status:           sysfsString(filepath.Join(dir, "status")),
+		Capacity:         -1,
+		CapacityLevel:    sysfsString(filepath.Join(dir, "capacity_level")),
+		EnergyNow:        micro("energy_now"),
+		EnergyFull:       micro("energy_full"),
+		EnergyFullDesign: micro("energy_full_design"),
+		ChargeNow:        micro("charge_now"),
+		ChargeFull:       micro("charge_full"),
+		ChargeFullDesign: micro("charge_full_design"),
+		Voltage:          micro("voltage_now"),
+		VoltageMinDesign: micro("voltage_min_design"),
+		Current:          micro("current_now"),
+		Power:            micro("power_now"),
+		CycleCount:       sysfsInt(filepath.Join(dir, "cycle_count")),
+		Technology:       sysfsString(filepath.Join(dir, "technology")),
+		Manufacturer:     sysfsString(filepath.Join(dir, "manufacturer")),
+		ModelName:        sysfsString(filepath.Join(dir, "model_name")),
+		SerialNumber:     sysfsString(filepath.Join(dir, "serial_number")),
+		TimeToEmptyNow:   time.Duration(sysfsInt(filepath.Join(dir, "time_to_empty_now"))) * time.Second,
+		TimeToFullNow:    time.Duration(sysfsInt(filepath.Join(dir, "time_to_full_now"))) * time.Second,
+	}
+
+	capacity, err := strconv.Atoi(sysfsString(filepath.Join(dir, "capacity")))
+	if err == nil {
+		ps.Capacity = capacity
+	}
+
+	return ps
+}
@@ -0,0 +1,111 @@
+package libsysinfo
+
+import (
+	"time"
+
+	. "launchpad.net/gocheck"
+)
+
+type PowerTestSuite struct{}
+
+var (
+	_ = Suite(&PowerTestSuite{})
+)
+
+func (s *PowerTestSuite) TestReadPowerSupplies(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"AC/type":                 "Mains\n",
+		"AC/online":               "0\n",
+		"BAT0/type":               "Battery\n",
+		"BAT0/present":            "1\n",
+		"BAT0/status":             "Discharging\n",
+		"BAT0/capacity":           "80\n",
+		"BAT0/capacity_level":     "Normal\n",
+		"BAT0/energy_now":         "40000000\n",
+		"BAT0/energy_full":        "50000000\n",
+		"BAT0/energy_full_design": "57000000\n",
+		"BAT0/voltage_now":        "12500000\n",
+		"BAT0/voltage_min_design": "11400000\n",
+		"BAT0/power_now":          "10000000\n",
+		"BAT0/cycle_count":        "312\n",
+		"BAT0/technology":         "Li-ion\n",
+		"BAT0/manufacturer":       "SMP\n",
+		"BAT0/model_name":         "5B10W13930\n",
+		"BAT0/serial_number":      "1234\n",
+	})
+
+	obtained := readPowerSupplies(root)
+
+	expected := []PowerSupply{
+		PowerSupply{
+			Name:     "AC",
+			Type:     PowerSupplyMains,
+			Capacity: -1,
+		},
+		PowerSupply{
+			Name:             "BAT0",
+			Type:             PowerSupplyBattery,
+			Present:          true,
+			Status:           BatteryDischarging,
+			Capacity:         80,
+			CapacityLevel:    "Normal",
+			EnergyNow:        40,
+			EnergyFull:       50,
+			EnergyFullDesign: 57,
+			Voltage:          12.5,
+			VoltageMinDesign: 11.4,
+			Power:            10,
+			CycleCount:       312,
+			Technology:       "Li-ion",
+			Manufacturer:     "SMP",
+			ModelName:        "5B10W13930",
+			SerialNumber:     "1234",
+		},
+	}
+
+	c.Assert(obtained, DeepEquals, expected)
+
+	bat := obtained[1]
+	c.Assert(bat.Health() > 87.7 && bat.Health() < 87.8, Equals, true)
+	c.Assert(bat.PowerDraw(), Equals, float64(10))
+	c.Assert(bat.TimeToEmpty(), Equals, 4*time.Hour)
+	c.Assert(bat.TimeToFull(), Equals, time.Duration(0))
+}
+
+func (s *PowerTestSuite) TestTimeEstimates_Charge(c *C) {
+	ps := PowerSupply{
+		Status:           BatteryCharging,
+		ChargeNow:        1,
+		ChargeFull:       3,
+		ChargeFullDesign: 4,
+		Current:          -0.5,
+	}
+
+	c.Assert(ps.Health(), Equals, float64(75))
+	c.Assert(ps.TimeToFull(), Equals, 4*time.Hour)
+	c.Assert(ps.TimeToEmpty(), Equals, time.Duration(0))
+
+	ps.Status = BatteryDischarging
+	c.Assert(ps.TimeToEmpty(), Equals, 2*time.Hour)
+
+	ps.Current = 0
+	c.Assert(ps.TimeToEmpty(), Equals, time.Duration(0))
+}
+
+func (s *PowerTestSuite) TestTimeEstimates_Driver(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"BAT1/status":            "Discharging\n",
+		"BAT1/energy_now":        "40000000\n",
+		"BAT1/power_now":         "10000000\n",
+		"BAT1/time_to_empty_now": "5400\n",
+	})
+
+	ps := readPowerSupply(root, "BAT1")
+
+	c.Assert(ps.TimeToEmptyNow, Equals, 90*time.Minute)
+	c.Assert(ps.TimeToEmpty(), Equals, 90*time.Minute)
+}
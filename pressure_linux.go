@@ -0,0 +1,361 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	PressureCPU    = "cpu"
+	PressureMemory = "memory"
+	PressureIO     = "io"
+	PressureIRQ    = "irq"
+)
+
+const (
+	// Share of time during which at least one task was stalled
+	PressureSome = "some"
+
+	// Share of time during which all non idle tasks were stalled
+	PressureFull = "full"
+)
+
+const (
+	pressurePath = "/proc/pressure"
+
+	// Trigger windows accepted by the kernel
+	psiMinWindow = 500 * time.Millisecond
+	psiMaxWindow = 10 * time.Second
+)
+
+var (
+	ErrPSINotAvailable   = &LibSysInfoErr{"Pressure stall information not available"}
+	ErrInvalidPSITrigger = &LibSysInfoErr{"Invalid pressure stall trigger"}
+)
+
+// ----
+
+type PressureStat struct {
+	// Percentage of stalled time over the last 10, 60 and 300 seconds
+	Avg10  float64
+	Avg60  float64
+	Avg300 float64
+
+	// Total stalled time in microseconds
+	Total uint64
+}
+
+type Pressure struct {
+	Some PressureStat
+
+	// Not reported for the CPU on kernels older than 5.13
+	Full PressureStat
+}
+
+type PSIStats struct {
+	CPU    Pressure
+	Memory Pressure
+	IO     Pressure
+
+	// Only available on kernels >= 6.1 built with CONFIG_IRQ_TIME_ACCOUNTING,
+	// only the Full line is meaningful
+	IRQ Pressure
+}
+
+type PSIEvent struct {
+	Resource string
+	Time     time.Time
+}
+
+// A kernel pressure stall trigger. An event is sent on Events every time the
+// stalled time exceeds the threshold within the window, at most once per
+// window.
+type PSITrigger struct {
+	Resource  string
+	Kind      string
+	Threshold time.Duration
+	Window    time.Duration
+
+	// Closed when the trigger is closed or when the kernel invalidates it,
+	// e.g. because the cgroup has been removed
+	Events chan PSIEvent
+
+	f     *os.File
+	epfd  int
+	wakeR int
+	wakeW int
+
+	// Protects the file descriptors once the trigger is running
+	mu     sync.Mutex
+	closed bool
+}
+
+// ----
+
+// Returns the system wide pressure stall information
+func PSI() (PSIStats, error) {
+	return readPSI(pressurePath, "")
+}
+
+// Returns the pressure stall information of a cgroup v2 group, e.g.
+// /sys/fs/cgroup/system.slice
+func CgroupPSI(cgroupPath string) (PSIStats, error) {
+	return readPSI(cgroupPath, ".pressure")
+}
+
+// Registers a system wide trigger for resource (PressureCPU, PressureMemory,
+// PressureIO or PressureIRQ). kind is either PressureSome or PressureFull.
+func NewPSITrigger(resource string, kind string, threshold time.Duration, window time.Duration) (*PSITrigger, error) {
+	return newPSITrigger(filepath.Join(pressurePath, resource), resource, kind, threshold, window)
+}
+
+// Same as NewPSITrigger for the pressure of a cgroup v2 group
+func NewCgroupPSITrigger(cgroupPath string, resource string, kind string, threshold time.Duration, window time.Duration) (*PSITrigger, error) {
+	path := filepath.Join(cgroupPath, resource+".pressure")
+	return newPSITrigger(path, resource, kind, threshold, window)
+}
+
+// Unregisters the trigger and closes Events
+func (t *PSITrigger) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+
+	_, err := syscall.Write(t.wakeW, []byte{0})
+	return err
+}
+
+// ----
+
+func readPSI(dir string, suffix string) (PSIStats, error) {
+	var stats PSIStats
+
+	resources := map[string]*Pressure{
+		PressureCPU:    &stats.CPU,
+		PressureMemory: &stats.Memory,
+		PressureIO:     &stats.IO,
+		PressureIRQ:    &stats.IRQ,
+	}
+
+	found := 0
+	for resource, p := range resources {
+		buff, err := readFile(filepath.Join(dir, resource+suffix))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return stats, err
+		}
+
+		*p = processPressure(buff)
+		found++
+	}
+
+	if found == 0 {
+		return stats, ErrPSINotAvailable
+	}
+
+	return stats, nil
+}
+
+func processPressure(buff string) Pressure {
+	var p Pressure
+
+	for _, line := range strings.Split(buff, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 5 {
+			continue
+		}
+
+		var ps PressureStat
+		for _, f := range fields[1:] {
+			kv := strings.Split(f, "=")
+			if len(kv) != 2 {
+				continue
+			}
+
+			switch kv[0] {
+			case "avg10":
+				ps.Avg10, _ = strconv.ParseFloat(kv[1], 64)
+			case "avg60":
+				ps.Avg60, _ = strconv.ParseFloat(kv[1], 64)
+			case "avg300":
+				ps.Avg300, _ = strconv.ParseFloat(kv[1], 64)
+			case "total":
+				ps.Total, _ = strconv.ParseUint(kv[1], 10, 64)
+			}
+		}
+
+		switch fields[0] {
+		case PressureSome:
+			p.Some = ps
+		case PressureFull:
+			p.Full = ps
+		}
+	}
+
+	return p
+}
+
+// Formats the trigger written to a pressure file, e.g. "some 150000 1000000"
+func psiTriggerSpec(kind string, threshold time.Duration, window time.Duration) (string, error) {
+	if kind != PressureSome && kind != PressureFull {
+		return "", ErrInvalidPSITrigger
+	}
+
+	if window < psiMinWindow || window > psiMaxWindow {
+		return "", ErrInvalidPSITrigger
+	}
+
+	if threshold <= 0 || threshold > window {
+		return "", ErrInvalidPSITrigger
+	}
+
+	us := func(d time.Duration) string {
+		return strconv.FormatInt(int64(d/time.Microsecond), 10)
+	}
+
+	return kind + " " + us(threshold) + " " + us(window), nil
+}
+
+func newPSITrigger(path string, resource string, kind string, threshold time.Duration, window time.Duration) (*PSITrigger, error) {
+	spec, err := psiTriggerSpec(kind, threshold, window)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// the trigger must be written in a single write, including the
+	// terminating NUL byte
+	_, err = f.Write(append([]byte(spec), 0))
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	t := &PSITrigger{
+		Resource:  resource,
+		Kind:      kind,
+		Threshold: threshold,
+		Window:    window,
+		Events:    make(chan PSIEvent, 1),
+		f:         f,
+	}
+
+	err = t.setupPoll()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	go t.loop()
+
+	return t, nil
+}
+
+func (t *PSITrigger) setupPoll() error {
+	var pipe [2]int
+
+	err := syscall.Pipe2(pipe[:], syscall.O_CLOEXEC)
+	if err != nil {
+		return err
+	}
+
+	epfd, err := syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
+	if err != nil {
+		syscall.Close(pipe[0])
+		syscall.Close(pipe[1])
+		return err
+	}
+
+	t.epfd = epfd
+	t.wakeR = pipe[0]
+	t.wakeW = pipe[1]
+
+	events := map[int]uint32{
+		int(t.f.Fd()): syscall.EPOLLPRI,
+		t.wakeR:       syscall.EPOLLIN,
+	}
+
+	for fd, ev := range events {
+		err = syscall.EpollCtl(epfd, syscall.EPOLL_CTL_ADD, fd, &syscall.EpollEvent{
+			Events: ev,
+			Fd:     int32(fd),
+		})
+		if err != nil {
+			syscall.Close(epfd)
+			syscall.Close(pipe[0])
+			syscall.Close(pipe[1])
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *PSITrigger) shutdown() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.closed = true
+
+	syscall.Close(t.epfd)
+	syscall.Close(t.wakeR)
+	syscall.Close(t.wakeW)
+	t.f.Close()
+
+	close(t.Events)
+}
+
+func (t *PSITrigger) loop() {
+	defer t.shutdown()
+
+	events := make([]syscall.EpollEvent, 2)
+	triggerFd := int32(t.f.Fd())
+
+	for {
+		n, err := syscall.EpollWait(t.epfd, events, -1)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil {
+			return
+		}
+
+		for _, ev := range events[:n] {
+			if ev.Fd != triggerFd {
+				// Close() has been called
+				return
+			}
+
+			if ev.Events&syscall.EPOLLERR != 0 {
+				return
+			}
+
+			if ev.Events&syscall.EPOLLPRI == 0 {
+				continue
+			}
+
+			// drop the event if the previous one has not been consumed
+			select {
+			case t.Events <- PSIEvent{Resource: t.Resource, Time: time.Now()}:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package libsysinfo
+
+import (
+	. "launchpad.net/gocheck"
+	"os"
+	"syscall"
+	"time"
+)
+
+type PressureTestSuite struct{}
+
+var (
+	_ = Suite(&PressureTestSuite{})
+)
+
+func (s *PressureTestSuite) TestProcessPressure(c *C) {
+	fixture := `some avg10=1.23 avg60=1.33 avg300=1.31 total=11534517
+full avg10=0.05 avg60=0.00 avg300=0.03 total=1478627
+`
+	obtained := processPressure(fixture)
+
+	expected := Pressure{
+		Some: PressureStat{Avg10: 1.23, Avg60: 1.33, Avg300: 1.31, Total: 11534517},
+		Full: PressureStat{Avg10: 0.05, Avg60: 0, Avg300: 0.03, Total: 1478627},
+	}
+
+	c.Assert(obtained, DeepEquals, expected)
+}
+
+func (s *PressureTestSuite) TestReadPSI(c *C) {
+	root := c.MkDir()
+
+	_, err := readPSI(root, ".pressure")
+	c.Assert(err, Equals, ErrPSINotAvailable)
+
+	writeFixtureFiles(c, root, map[string]string{
+		"cpu.pressure":    "some avg10=2.00 avg60=1.00 avg300=0.50 total=100\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n",
+		"memory.pressure": "some avg10=0.00 avg60=0.00 avg300=0.00 total=42\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=24\n",
+		"io.pressure":     "some avg10=0.00 avg60=0.00 avg300=0.00 total=7\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=3\n",
+	})
+
+	obtained, err := readPSI(root, ".pressure")
+	c.Assert(err, IsNil)
+	c.Assert(obtained.CPU.Some.Avg10, Equals, 2.0)
+	c.Assert(obtained.Memory.Full.Total, Equals, uint64(24))
+	c.Assert(obtained.IO.Some.Total, Equals, uint64(7))
+	c.Assert(obtained.IRQ, DeepEquals, Pressure{})
+}
+
+func (s *PressureTestSuite) TestPSITriggerSpec(c *C) {
+	obtained, err := psiTriggerSpec(PressureSome, 150*time.Millisecond, time.Second)
+	c.Assert(err, IsNil)
+	c.Assert(obtained, Equals, "some 150000 1000000")
+
+	invalid := []struct {
+		kind      string
+		threshold time.Duration
+		window    time.Duration
+	}{
+		{"partial", 150 * time.Millisecond, time.Second},
+		{PressureFull, 150 * time.Millisecond, 100 * time.Millisecond},
+		{PressureFull, 150 * time.Millisecond, time.Minute},
+		{PressureFull, 2 * time.Second, time.Second},
+		{PressureFull, 0, time.Second},
+	}
+
+	for _, t := range invalid {
+		_, err = psiTriggerSpec(t.kind, t.threshold, t.window)
+		c.Assert(err, Equals, ErrInvalidPSITrigger)
+	}
+}
+
+func (s *PressureTestSuite) TestPSITriggerPoll(c *C) {
+	// a pipe stands for the pressure file, it never reports EPOLLPRI
+	r, w, err := os.Pipe()
+	c.Assert(err, IsNil)
+	defer w.Close()
+
+	t := &PSITrigger{
+		Resource: PressureMemory,
+		Events:   make(chan PSIEvent, 1),
+		f:        r,
+	}
+
+	c.Assert(t.setupPoll(), IsNil)
+
+	// the wakeup pipe must not leak into child processes
+	for _, fd := range []int{t.wakeR, t.wakeW} {
+		flags, _, errno := syscall.Syscall(syscall.SYS_FCNTL, uintptr(fd), syscall.F_GETFD, 0)
+		c.Assert(errno, Equals, syscall.Errno(0))
+		c.Assert(flags&syscall.FD_CLOEXEC, Equals, uintptr(syscall.FD_CLOEXEC))
+	}
+
+	go t.loop()
+
+	c.Assert(t.Close(), IsNil)
+
+	select {
+	case _, ok := <-t.Events:
+		c.Assert(ok, Equals, false)
+	case <-time.After(5 * time.Second):
+		c.Fatal("Events not closed")
+	}
+
+	c.Assert(t.Close(), IsNil)
+}
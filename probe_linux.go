@@ -0,0 +1,534 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Types as named by blkid
+const (
+	ProbeExt2     = "ext2"
+	ProbeExt3     = "ext3"
+	ProbeExt4     = "ext4"
+	ProbeXFS      = "xfs"
+	ProbeBtrfs    = "btrfs"
+	ProbeVFAT     = "vfat"
+	ProbeExFAT    = "exfat"
+	ProbeNTFS     = "ntfs"
+	ProbeSwap     = "swap"
+	ProbeLUKS     = "crypto_LUKS"
+	ProbeLVM2     = "LVM2_member"
+	ProbeISO9660  = "iso9660"
+	ProbeSquashFS = "squashfs"
+)
+
+const (
+	// ext2/3/4 features, see fs/ext4/ext4.h
+	extCompatHasJournal     = 0x0004
+	extIncompatFiletype     = 0x0002
+	extIncompatRecover      = 0x0004
+	extIncompatJournalDev   = 0x0008
+	extIncompatMetaBG       = 0x0010
+	extIncompat64Bit        = 0x0080
+	extROCompatSparseSuper  = 0x0001
+	extROCompatLargeFile    = 0x0002
+	extROCompatBtreeDir     = 0x0004
+	extIncompatExt3Features = extIncompatFiletype | extIncompatRecover | extIncompatMetaBG
+	extROCompatExt3Features = extROCompatSparseSuper | extROCompatLargeFile | extROCompatBtreeDir
+)
+
+var (
+	ErrUnknownFileSystem = &LibSysInfoErr{"No known filesystem signature found"}
+
+	// Probers are tried in order, the ones checking a magic at a fixed
+	// offset first and FAT, whose detection is heuristic, last
+	probers = []func(p prober) (ProbeInfo, bool){
+		probeLUKS,
+		probeLVM2,
+		probeSwap,
+		probeXFS,
+		probeBtrfs,
+		probeExt,
+		probeISO9660,
+		probeSquashFS,
+		probeNTFS,
+		probeExFAT,
+		probeVFAT,
+	}
+)
+
+// ----
+
+// What lies on a block device or an image file
+type ProbeInfo struct {
+	// One of the Probe* constants
+	Type string
+
+	// Format version when relevant, e.g. FAT32 or 2 for LUKS2
+	Version string
+
+	// UUID or serial number, formatted as blkid does. May be empty.
+	UUID  string
+	Label string
+
+	// In bytes, zero when unknown
+	Size      uint64
+	BlockSize int
+}
+
+// ----
+
+// Identifies the filesystem, swap area or volume header found on a block
+// device or an image file
+func Probe(path string) (ProbeInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ProbeInfo{}, err
+	}
+	defer f.Close()
+
+	return ProbeReader(f)
+}
+
+func ProbeReader(r io.ReaderAt) (ProbeInfo, error) {
+	p := prober{r}
+
+	for _, probe := range probers {
+		info, found := probe(p)
+		if found {
+			return info, nil
+		}
+	}
+
+	return ProbeInfo{}, ErrUnknownFileSystem
+}
+
+// ----
+
+type prober struct {
+	r io.ReaderAt
+}
+
+// Returns n bytes at off, nil when they can not be read
+func (p prober) at(off int64, n int) []byte {
+	buff := make([]byte, n)
+
+	_, err := p.r.ReadAt(buff, off)
+	if err != nil {
+		return nil
+	}
+
+	return buff
+}
+
+// Decodes a NUL or space padded string
+func paddedString(b []byte) string {
+	return strings.TrimRight(cString(b), " ")
+}
+
+// Formats 16 bytes as a big endian UUID
+func formatUUIDBytes(b []byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Formats the 32 bits serial numbers of FAT and exFAT
+func formatVolumeSerial(id uint32) string {
+	return fmt.Sprintf("%04X-%04X", id>>16, id&0xffff)
+}
+
+func probeLUKS(p prober) (ProbeInfo, bool) {
+	hdr := p.at(0, 592)
+	if hdr == nil || !bytes.Equal(hdr[0:6], []byte("LUKS\xba\xbe")) {
+		return ProbeInfo{}, false
+	}
+
+	info := ProbeInfo{
+		Type:    ProbeLUKS,
+		Version: fmt.Sprintf("%d", binary.BigEndian.Uint16(hdr[6:])),
+		UUID:    paddedString(hdr[168:208]),
+	}
+
+	// only LUKS2 headers hold a label
+	if info.Version == "2" {
+		info.Label = paddedString(hdr[24:72])
+	}
+
+	return info, true
+}
+
+// The LVM label is in one of the first 4 sectors, it points to the PV header
+// holding the PV UUID and the device size
+func probeLVM2(p prober) (ProbeInfo, bool) {
+	for sector := int64(0); sector < 4; sector++ {
+		label := p.at(sector*512, 512)
+		if label == nil {
+			return ProbeInfo{}, false
+		}
+
+		if string(label[0:8]) != "LABELONE" || string(label[24:32]) != "LVM2 001" {
+			continue
+		}
+
+		offset := binary.LittleEndian.Uint32(label[20:])
+		if int64(offset)+40 > 512 {
+			return ProbeInfo{}, false
+		}
+
+		pvHeader := label[offset:]
+
+		return ProbeInfo{
+			Type:    ProbeLVM2,
+			Version: "LVM2 001",
+			UUID:    formatLVMUUID(string(pvHeader[0:32])),
+			Size:    binary.LittleEndian.Uint64(pvHeader[32:]),
+		}, true
+	}
+
+	return ProbeInfo{}, false
+}
+
+// The signature ends the first page, whose size depends on the architecture
+// which created the swap area
+func probeSwap(p prober) (ProbeInfo, bool) {
+	for _, pageSize := range []int64{4096, 8192, 16384, 65536} {
+		sig := p.at(pageSize-10, 10)
+		if sig == nil {
+			return ProbeInfo{}, false
+		}
+
+		switch string(sig) {
+		case "SWAP-SPACE":
+			return ProbeInfo{Type: ProbeSwap, Version: "0", BlockSize: int(pageSize)}, true
+
+		case "SWAPSPACE2":
+			hdr := p.at(1024, 44)
+			if hdr == nil {
+				return ProbeInfo{}, false
+			}
+
+			lastPage := binary.LittleEndian.Uint32(hdr[4:])
+
+			return ProbeInfo{
+				Type:      ProbeSwap,
+				Version:   fmt.Sprintf("%d", binary.LittleEndian.Uint32(hdr[0:])),
+				UUID:      formatUUIDBytes(hdr[12:28]),
+				Label:     paddedString(hdr[28:44]),
+				Size:      uint64(lastPage+1) * uint64(pageSize),
+				BlockSize: int(pageSize),
+			}, true
+		}
+	}
+
+	return ProbeInfo{}, false
+}
+
+func probeXFS(p prober) (ProbeInfo, bool) {
+	sb := p.at(0, 120)
+	if sb == nil || string(sb[0:4]) != "XFSB" {
+		return ProbeInfo{}, false
+	}
+
+	blockSize := binary.BigEndian.Uint32(sb[4:])
+
+	return ProbeInfo{
+		Type:      ProbeXFS,
+		UUID:      formatUUIDBytes(sb[32:48]),
+		Label:     paddedString(sb[108:120]),
+		Size:      binary.BigEndian.Uint64(sb[8:]) * uint64(blockSize),
+		BlockSize: int(blockSize),
+	}, true
+}
+
+func probeBtrfs(p prober) (ProbeInfo, bool) {
+	sb := p.at(0x10000, 0x22b)
+	if sb == nil || string(sb[0x40:0x48]) != "_BHRfS_M" {
+		return ProbeInfo{}, false
+	}
+
+	return ProbeInfo{
+		Type:      ProbeBtrfs,
+		UUID:      formatUUIDBytes(sb[0x20:0x30]),
+		Label:     paddedString(sb[0x12b:0x22b]),
+		Size:      binary.LittleEndian.Uint64(sb[0x70:]),
+		BlockSize: int(binary.LittleEndian.Uint32(sb[0x90:])),
+	}, true
+}
+
+func probeExt(p prober) (ProbeInfo, bool) {
+	sb := p.at(1024, 1024)
+	if sb == nil || binary.LittleEndian.Uint16(sb[56:]) != 0xef53 {
+		return ProbeInfo{}, false
+	}
+
+	compat := binary.LittleEndian.Uint32(sb[92:])
+	incompat := binary.LittleEndian.Uint32(sb[96:])
+	roCompat := binary.LittleEndian.Uint32(sb[100:])
+
+	// external journals are not filesystems
+	if incompat&extIncompatJournalDev != 0 {
+		return ProbeInfo{}, false
+	}
+
+	blockSize := uint64(1024) << binary.LittleEndian.Uint32(sb[24:])
+
+	blocks := uint64(binary.LittleEndian.Uint32(sb[4:]))
+	if incompat&extIncompat64Bit != 0 {
+		blocks |= uint64(binary.LittleEndian.Uint32(sb[0x150:])) << 32
+	}
+
+	info := ProbeInfo{
+		Type:      ProbeExt2,
+		Version:   fmt.Sprintf("%d.%d", binary.LittleEndian.Uint32(sb[76:]), binary.LittleEndian.Uint16(sb[62:])),
+		UUID:      formatUUIDBytes(sb[104:120]),
+		Label:     paddedString(sb[120:136]),
+		Size:      blocks * blockSize,
+		BlockSize: int(blockSize),
+	}
+
+	// like blkid, features ext3 does not support make it ext4
+	switch {
+	case incompat&^extIncompatExt3Features != 0, roCompat&^extROCompatExt3Features != 0:
+		info.Type = ProbeExt4
+	case compat&extCompatHasJournal != 0:
+		info.Type = ProbeExt3
+	}
+
+	return info, true
+}
+
+func probeISO9660(p prober) (ProbeInfo, bool) {
+	pvd := p.at(0x8000, 847)
+	if pvd == nil || pvd[0] != 1 || string(pvd[1:6]) != "CD001" {
+		return ProbeInfo{}, false
+	}
+
+	blockSize := binary.LittleEndian.Uint16(pvd[128:])
+
+	info := ProbeInfo{
+		Type:      ProbeISO9660,
+		Label:     paddedString(pvd[40:72]),
+		Size:      uint64(binary.LittleEndian.Uint32(pvd[80:])) * uint64(blockSize),
+		BlockSize: int(blockSize),
+	}
+
+	// like blkid, the UUID is made of the modification or the creation
+	// date, e.g. 2023-01-15-10-30-00-00
+	for _, date := range [][]byte{pvd[830:846], pvd[813:829]} {
+		if strings.Trim(string(date), "0 \x00") == "" {
+			continue
+		}
+
+		d := string(date)
+		info.UUID = strings.Join([]string{d[0:4], d[4:6], d[6:8], d[8:10], d[10:12], d[12:14], d[14:16]}, "-")
+		break
+	}
+
+	return info, true
+}
+
+func probeSquashFS(p prober) (ProbeInfo, bool) {
+	sb := p.at(0, 48)
+	if sb == nil || string(sb[0:4]) != "hsqs" {
+		return ProbeInfo{}, false
+	}
+
+	return ProbeInfo{
+		Type:      ProbeSquashFS,
+		Version:   fmt.Sprintf("%d.%d", binary.LittleEndian.Uint16(sb[28:]), binary.LittleEndian.Uint16(sb[30:])),
+		Size:      binary.LittleEndian.Uint64(sb[40:]),
+		BlockSize: int(binary.LittleEndian.Uint32(sb[12:])),
+	}, true
+}
+
+func probeNTFS(p prober) (ProbeInfo, bool) {
+	bs := p.at(0, 512)
+	if bs == nil || string(bs[3:11]) != "NTFS    " {
+		return ProbeInfo{}, false
+	}
+
+	sectorSize := uint64(binary.LittleEndian.Uint16(bs[11:]))
+	clusterSize := sectorSize * uint64(bs[13])
+
+	info := ProbeInfo{
+		Type:      ProbeNTFS,
+		UUID:      fmt.Sprintf("%016X", binary.LittleEndian.Uint64(bs[72:])),
+		Size:      binary.LittleEndian.Uint64(bs[40:]) * sectorSize,
+		BlockSize: int(clusterSize),
+	}
+
+	// the size of MFT records is given in clusters, or as a power of two
+	// when negative
+	recordSize := uint64(int8(bs[64]))
+	if int8(bs[64]) < 0 {
+		recordSize = 1 << uint(-int8(bs[64]))
+	} else {
+		recordSize *= clusterSize
+	}
+
+	// do not walk the MFT with a corrupted geometry
+	if sectorSize < 256 || sectorSize > 4096 || sectorSize&(sectorSize-1) != 0 {
+		return info, true
+	}
+
+	if recordSize < sectorSize || recordSize > 65536 {
+		return info, true
+	}
+
+	// the label is an attribute of $Volume, the 4th record of the MFT
+	mft := binary.LittleEndian.Uint64(bs[48:]) * clusterSize
+	record := p.at(int64(mft+3*recordSize), int(recordSize))
+	if record != nil {
+		info.Label = ntfsVolumeName(record, int(sectorSize))
+	}
+
+	return info, true
+}
+
+// Extracts the $VOLUME_NAME attribute of a $Volume MFT record
+func ntfsVolumeName(record []byte, sectorSize int) string {
+	if string(record[0:4]) != "FILE" {
+		return ""
+	}
+
+	// the last 2 bytes of each sector were moved to the update sequence
+	// array when the record was written
+	usaOffset := int(binary.LittleEndian.Uint16(record[4:]))
+	usaCount := int(binary.LittleEndian.Uint16(record[6:]))
+	for i := 1; i < usaCount; i++ {
+		end := i * sectorSize
+		if end > len(record) || usaOffset+2*i+2 > len(record) {
+			return ""
+		}
+
+		copy(record[end-2:end], record[usaOffset+2*i:usaOffset+2*i+2])
+	}
+
+	offset := int(binary.LittleEndian.Uint16(record[20:]))
+	for offset+24 <= len(record) {
+		attrType := binary.LittleEndian.Uint32(record[offset:])
+		attrLen := uint64(binary.LittleEndian.Uint32(record[offset+4:]))
+
+		if attrType == 0xffffffff || attrLen == 0 || attrLen > uint64(len(record)-offset) {
+			break
+		}
+
+		// $VOLUME_NAME, always resident
+		if attrType == 0x60 {
+			valueLen := uint64(binary.LittleEndian.Uint32(record[offset+16:]))
+			valueOffset := uint64(binary.LittleEndian.Uint16(record[offset+20:]))
+
+			start := uint64(offset) + valueOffset
+			if start+valueLen > uint64(len(record)) {
+				return ""
+			}
+
+			return decodeUTF16LE(record[start : start+valueLen])
+		}
+
+		offset += int(attrLen)
+	}
+
+	return ""
+}
+
+func probeExFAT(p prober) (ProbeInfo, bool) {
+	bs := p.at(0, 512)
+	if bs == nil || string(bs[3:11]) != "EXFAT   " {
+		return ProbeInfo{}, false
+	}
+
+	sectorSize := uint64(1) << bs[108]
+	clusterSize := sectorSize << bs[109]
+
+	info := ProbeInfo{
+		Type:      ProbeExFAT,
+		UUID:      formatVolumeSerial(binary.LittleEndian.Uint32(bs[100:])),
+		Size:      binary.LittleEndian.Uint64(bs[72:]) * sectorSize,
+		BlockSize: int(clusterSize),
+	}
+
+	if clusterSize > 32*1024*1024 {
+		return info, true
+	}
+
+	// the label is an entry of the first cluster of the root directory
+	heap := uint64(binary.LittleEndian.Uint32(bs[88:])) * sectorSize
+	rootCluster := uint64(binary.LittleEndian.Uint32(bs[96:]))
+	if rootCluster < 2 {
+		return info, true
+	}
+
+	root := p.at(int64(heap+(rootCluster-2)*clusterSize), int(clusterSize))
+	for i := 0; i+32 <= len(root); i += 32 {
+		entry := root[i : i+32]
+
+		// end of directory
+		if entry[0] == 0x00 {
+			break
+		}
+
+		// volume label, with its length in characters
+		if entry[0] == 0x83 {
+			n := int(entry[1])
+			if n > 11 {
+				n = 11
+			}
+
+			info.Label = decodeUTF16LE(entry[2 : 2+2*n])
+			break
+		}
+	}
+
+	return info, true
+}
+
+func probeVFAT(p prober) (ProbeInfo, bool) {
+	bs := p.at(0, 512)
+	if bs == nil || binary.LittleEndian.Uint16(bs[510:]) != mbrSignature {
+		return ProbeInfo{}, false
+	}
+
+	sectorSize := uint64(binary.LittleEndian.Uint16(bs[11:]))
+	if sectorSize < 512 || sectorSize > 4096 || bs[13] == 0 {
+		return ProbeInfo{}, false
+	}
+
+	info := ProbeInfo{
+		Type:      ProbeVFAT,
+		BlockSize: int(sectorSize) * int(bs[13]),
+	}
+
+	sectors := uint64(binary.LittleEndian.Uint16(bs[19:]))
+	if sectors == 0 {
+		sectors = uint64(binary.LittleEndian.Uint32(bs[32:]))
+	}
+	info.Size = sectors * sectorSize
+
+	// FAT32 and FAT12/16 extended boot records differ
+	switch {
+	case string(bs[82:87]) == "FAT32":
+		info.Version = "FAT32"
+		info.UUID = formatVolumeSerial(binary.LittleEndian.Uint32(bs[67:]))
+		info.Label = paddedString(bs[71:82])
+
+	case string(bs[54:59]) == "FAT12" || string(bs[54:59]) == "FAT16":
+		info.Version = string(bs[54:59])
+		info.UUID = formatVolumeSerial(binary.LittleEndian.Uint32(bs[39:]))
+		info.Label = paddedString(bs[43:54])
+
+	default:
+		return ProbeInfo{}, false
+	}
+
+	if info.Label == "NO NAME" {
+		info.Label = ""
+	}
+
+	return info, true
+}
@@ -0,0 +1,432 @@
+package libsysinfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unicode/utf16"
+
+	. "launchpad.net/gocheck"
+)
+
+type ProbeTestSuite struct{}
+
+var (
+	_ = Suite(&ProbeTestSuite{})
+)
+
+var (
+	probeFixtureUUID = []byte{
+		0x5c, 0xbd, 0x08, 0xb9, 0x10, 0xe4, 0x46, 0xe7,
+		0x93, 0x92, 0xfd, 0x81, 0x63, 0x29, 0x9b, 0xd5,
+	}
+)
+
+func utf16LE(s string) []byte {
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u), byte(u>>8))
+	}
+
+	return b
+}
+
+func probeFixture(c *C, size int, writes func(img []byte)) ProbeInfo {
+	img := make([]byte, size)
+	writes(img)
+
+	info, err := ProbeReader(bytes.NewReader(img))
+	c.Assert(err, IsNil)
+
+	return info
+}
+
+func (s *ProbeTestSuite) TestProbeExt(c *C) {
+	ext := func(compat uint32, incompat uint32, roCompat uint32) func(img []byte) {
+		return func(img []byte) {
+			sb := img[1024:]
+			binary.LittleEndian.PutUint32(sb[4:], 16384)
+			binary.LittleEndian.PutUint32(sb[24:], 2)
+			binary.LittleEndian.PutUint16(sb[56:], 0xef53)
+			binary.LittleEndian.PutUint32(sb[76:], 1)
+			binary.LittleEndian.PutUint32(sb[92:], compat)
+			binary.LittleEndian.PutUint32(sb[96:], incompat)
+			binary.LittleEndian.PutUint32(sb[100:], roCompat)
+			copy(sb[104:], probeFixtureUUID)
+			copy(sb[120:], "rootfs")
+		}
+	}
+
+	info := probeFixture(c, 4096, ext(0, extIncompatFiletype, extROCompatSparseSuper))
+	c.Check(info, DeepEquals, ProbeInfo{
+		Type:      ProbeExt2,
+		Version:   "1.0",
+		UUID:      "5cbd08b9-10e4-46e7-9392-fd8163299bd5",
+		Label:     "rootfs",
+		Size:      16384 * 4096,
+		BlockSize: 4096,
+	})
+
+	info = probeFixture(c, 4096, ext(extCompatHasJournal, extIncompatFiletype|extIncompatRecover, 0))
+	c.Check(info.Type, Equals, ProbeExt3)
+
+	// extents
+	info = probeFixture(c, 4096, ext(extCompatHasJournal, extIncompatFiletype|0x40, 0))
+	c.Check(info.Type, Equals, ProbeExt4)
+
+	// metadata_csum
+	info = probeFixture(c, 4096, ext(0, 0, 0x400))
+	c.Check(info.Type, Equals, ProbeExt4)
+
+	// external journal
+	_, err := ProbeReader(bytes.NewReader(func() []byte {
+		img := make([]byte, 4096)
+		ext(0, extIncompatJournalDev, 0)(img)
+		return img
+	}()))
+	c.Check(err, Equals, ErrUnknownFileSystem)
+}
+
+func (s *ProbeTestSuite) TestProbeXFS(c *C) {
+	info := probeFixture(c, 4096, func(img []byte) {
+		copy(img, "XFSB")
+		binary.BigEndian.PutUint32(img[4:], 4096)
+		binary.BigEndian.PutUint64(img[8:], 262144)
+		copy(img[32:], probeFixtureUUID)
+		copy(img[108:], "data")
+	})
+
+	c.Check(info, DeepEquals, ProbeInfo{
+		Type:      ProbeXFS,
+		UUID:      "5cbd08b9-10e4-46e7-9392-fd8163299bd5",
+		Label:     "data",
+		Size:      262144 * 4096,
+		BlockSize: 4096,
+	})
+}
+
+func (s *ProbeTestSuite) TestProbeBtrfs(c *C) {
+	info := probeFixture(c, 0x11000, func(img []byte) {
+		sb := img[0x10000:]
+		copy(sb[0x20:], probeFixtureUUID)
+		copy(sb[0x40:], "_BHRfS_M")
+		binary.LittleEndian.PutUint64(sb[0x70:], 1<<30)
+		binary.LittleEndian.PutUint32(sb[0x90:], 4096)
+		copy(sb[0x12b:], "pool")
+	})
+
+	c.Check(info, DeepEquals, ProbeInfo{
+		Type:      ProbeBtrfs,
+		UUID:      "5cbd08b9-10e4-46e7-9392-fd8163299bd5",
+		Label:     "pool",
+		Size:      1 << 30,
+		BlockSize: 4096,
+	})
+}
+
+func (s *ProbeTestSuite) TestProbeVFAT(c *C) {
+	info := probeFixture(c, 4096, func(img []byte) {
+		binary.LittleEndian.PutUint16(img[11:], 512)
+		img[13] = 8
+		binary.LittleEndian.PutUint32(img[32:], 1048576)
+		binary.LittleEndian.PutUint32(img[67:], 0x1a2b3c4d)
+		copy(img[71:], "EFI        ")
+		copy(img[82:], "FAT32   ")
+		binary.LittleEndian.PutUint16(img[510:], mbrSignature)
+	})
+
+	c.Check(info, DeepEquals, ProbeInfo{
+		Type:      ProbeVFAT,
+		Version:   "FAT32",
+		UUID:      "1A2B-3C4D",
+		Label:     "EFI",
+		Size:      1048576 * 512,
+		BlockSize: 4096,
+	})
+
+	info = probeFixture(c, 4096, func(img []byte) {
+		binary.LittleEndian.PutUint16(img[11:], 512)
+		img[13] = 4
+		binary.LittleEndian.PutUint16(img[19:], 20480)
+		binary.LittleEndian.PutUint32(img[39:], 0xdeadbeef)
+		copy(img[43:], "NO NAME    ")
+		copy(img[54:], "FAT16   ")
+		binary.LittleEndian.PutUint16(img[510:], mbrSignature)
+	})
+
+	c.Check(info, DeepEquals, ProbeInfo{
+		Type:      ProbeVFAT,
+		Version:   "FAT16",
+		UUID:      "DEAD-BEEF",
+		Size:      20480 * 512,
+		BlockSize: 2048,
+	})
+}
+
+func (s *ProbeTestSuite) TestProbeExFAT(c *C) {
+	info := probeFixture(c, 8192, func(img []byte) {
+		copy(img[3:], "EXFAT   ")
+		binary.LittleEndian.PutUint64(img[72:], 131072)
+		binary.LittleEndian.PutUint32(img[88:], 8)
+		binary.LittleEndian.PutUint32(img[96:], 4)
+		binary.LittleEndian.PutUint32(img[100:], 0x0c1d2e3f)
+		img[108] = 9
+		img[109] = 1
+
+		// root directory in the third cluster of the heap
+		root := img[8*512+2*1024:]
+		root[0] = 0x81
+		root[32] = 0x83
+		root[33] = 5
+		copy(root[34:], utf16LE("USBKY"))
+	})
+
+	c.Check(info, DeepEquals, ProbeInfo{
+		Type:      ProbeExFAT,
+		UUID:      "0C1D-2E3F",
+		Label:     "USBKY",
+		Size:      131072 * 512,
+		BlockSize: 1024,
+	})
+}
+
+func (s *ProbeTestSuite) TestProbeNTFS(c *C) {
+	info := probeFixture(c, 16384, func(img []byte) {
+		copy(img[3:], "NTFS    ")
+		binary.LittleEndian.PutUint16(img[11:], 512)
+		img[13] = 8
+		binary.LittleEndian.PutUint64(img[40:], 2097151)
+		binary.LittleEndian.PutUint64(img[48:], 1)
+
+		// 1024 bytes records
+		img[64] = 0xf6
+		binary.LittleEndian.PutUint64(img[72:], 0x12ab34cd56ef7890)
+
+		// $Volume, with the update sequence array protecting the end of
+		// both of its sectors
+		record := img[4096+3*1024:]
+		copy(record, "FILE")
+		binary.LittleEndian.PutUint16(record[4:], 48)
+		binary.LittleEndian.PutUint16(record[6:], 3)
+		binary.LittleEndian.PutUint16(record[20:], 56)
+
+		// $STANDARD_INFORMATION
+		binary.LittleEndian.PutUint32(record[56:], 0x10)
+		binary.LittleEndian.PutUint32(record[60:], 408)
+
+		// $VOLUME_NAME, spanning the end of the first sector
+		name := utf16LE("Windows Data")
+		attr := record[56+408:]
+		binary.LittleEndian.PutUint32(attr[0:], 0x60)
+		binary.LittleEndian.PutUint32(attr[4:], uint32(24+len(name)+4))
+		binary.LittleEndian.PutUint32(attr[16:], uint32(len(name)))
+		binary.LittleEndian.PutUint16(attr[20:], 24)
+		copy(attr[24:], name)
+		binary.LittleEndian.PutUint32(attr[24+len(name)+4:], 0xffffffff)
+
+		copy(record[48+2:], record[510:512])
+		copy(record[510:], []byte{0x42, 0x42})
+		copy(record[48+4:], record[1022:1024])
+		copy(record[1022:], []byte{0x42, 0x42})
+	})
+
+	c.Check(info, DeepEquals, ProbeInfo{
+		Type:      ProbeNTFS,
+		UUID:      "12AB34CD56EF7890",
+		Label:     "Windows Data",
+		Size:      2097151 * 512,
+		BlockSize: 4096,
+	})
+}
+
+func (s *ProbeTestSuite) TestProbeNTFS_Corrupted(c *C) {
+	// a $Volume record the label would be read from with a sane geometry
+	volume := func(img []byte, at int) {
+		record := img[at:]
+		copy(record, "FILE")
+		binary.LittleEndian.PutUint16(record[4:], 48)
+		binary.LittleEndian.PutUint16(record[6:], 3)
+		binary.LittleEndian.PutUint16(record[20:], 56)
+	}
+
+	// 1 byte sectors
+	info := probeFixture(c, 16384, func(img []byte) {
+		copy(img[3:], "NTFS    ")
+		binary.LittleEndian.PutUint16(img[11:], 1)
+		img[13] = 8
+		binary.LittleEndian.PutUint64(img[40:], 2097151)
+		binary.LittleEndian.PutUint64(img[48:], 1)
+		img[64] = 0xf6
+		volume(img, 8+3*1024)
+	})
+
+	c.Check(info, DeepEquals, ProbeInfo{
+		Type:      ProbeNTFS,
+		UUID:      "0000000000000000",
+		Size:      2097151,
+		BlockSize: 8,
+	})
+
+	// 2 bytes records
+	info = probeFixture(c, 16384, func(img []byte) {
+		copy(img[3:], "NTFS    ")
+		binary.LittleEndian.PutUint16(img[11:], 512)
+		img[13] = 8
+		binary.LittleEndian.PutUint64(img[48:], 1)
+		img[64] = 0xff
+		volume(img, 4096+3*2)
+	})
+
+	c.Check(info.Type, Equals, ProbeNTFS)
+	c.Check(info.Label, Equals, "")
+}
+
+func (s *ProbeTestSuite) TestNTFSVolumeName_Corrupted(c *C) {
+	record := make([]byte, 1024)
+	copy(record, "FILE")
+	binary.LittleEndian.PutUint16(record[20:], 56)
+
+	// $VOLUME_NAME with a value past the end of the record
+	binary.LittleEndian.PutUint32(record[56:], 0x60)
+	binary.LittleEndian.PutUint32(record[60:], 32)
+	binary.LittleEndian.PutUint32(record[72:], 0xfffffff0)
+	binary.LittleEndian.PutUint16(record[76:], 24)
+	c.Check(ntfsVolumeName(record, 512), Equals, "")
+
+	// attribute longer than the record
+	binary.LittleEndian.PutUint32(record[56:], 0x10)
+	binary.LittleEndian.PutUint32(record[60:], 0xfffffff0)
+	c.Check(ntfsVolumeName(record, 512), Equals, "")
+}
+
+func (s *ProbeTestSuite) TestProbeSwap(c *C) {
+	info := probeFixture(c, 8192, func(img []byte) {
+		binary.LittleEndian.PutUint32(img[1024:], 1)
+		binary.LittleEndian.PutUint32(img[1028:], 2047)
+		copy(img[1036:], probeFixtureUUID)
+		copy(img[1052:], "myswap")
+		copy(img[4086:], "SWAPSPACE2")
+	})
+
+	c.Check(info, DeepEquals, ProbeInfo{
+		Type:      ProbeSwap,
+		Version:   "1",
+		UUID:      "5cbd08b9-10e4-46e7-9392-fd8163299bd5",
+		Label:     "myswap",
+		Size:      2048 * 4096,
+		BlockSize: 4096,
+	})
+
+	// 64K pages
+	info = probeFixture(c, 65536, func(img []byte) {
+		copy(img[65526:], "SWAPSPACE2")
+	})
+	c.Check(info.Type, Equals, ProbeSwap)
+	c.Check(info.BlockSize, Equals, 65536)
+}
+
+func (s *ProbeTestSuite) TestProbeLUKS(c *C) {
+	info := probeFixture(c, 4096, func(img []byte) {
+		copy(img, "LUKS\xba\xbe")
+		binary.BigEndian.PutUint16(img[6:], 2)
+		copy(img[24:], "secrets")
+		copy(img[168:], "0f8e4b55-9d2c-4a6b-a4c5-2d8e2b1f3c7a")
+	})
+
+	c.Check(info, DeepEquals, ProbeInfo{
+		Type:    ProbeLUKS,
+		Version: "2",
+		UUID:    "0f8e4b55-9d2c-4a6b-a4c5-2d8e2b1f3c7a",
+		Label:   "secrets",
+	})
+
+	info = probeFixture(c, 4096, func(img []byte) {
+		copy(img, "LUKS\xba\xbe")
+		binary.BigEndian.PutUint16(img[6:], 1)
+		copy(img[24:], "cbc-essiv:sha256")
+		copy(img[168:], "0f8e4b55-9d2c-4a6b-a4c5-2d8e2b1f3c7a")
+	})
+
+	c.Check(info.Version, Equals, "1")
+	c.Check(info.Label, Equals, "")
+}
+
+func (s *ProbeTestSuite) TestProbeLVM2(c *C) {
+	info := probeFixture(c, 4096, func(img []byte) {
+		label := img[512:]
+		copy(label, "LABELONE")
+		binary.LittleEndian.PutUint32(label[20:], 32)
+		copy(label[24:], "LVM2 001")
+		copy(label[32:], "U1IGrHUVjCxRSwPhMiIb2VOv5L3efNyH")
+		binary.LittleEndian.PutUint64(label[64:], 1<<34)
+	})
+
+	c.Check(info, DeepEquals, ProbeInfo{
+		Type:    ProbeLVM2,
+		Version: "LVM2 001",
+		UUID:    "U1IGrH-UVjC-xRSw-PhMi-Ib2V-Ov5L-3efNyH",
+		Size:    1 << 34,
+	})
+}
+
+func (s *ProbeTestSuite) TestProbeLVM2_Corrupted(c *C) {
+	img := make([]byte, 4096)
+	label := img[512:]
+	copy(label, "LABELONE")
+	binary.LittleEndian.PutUint32(label[20:], 0xfffffff0)
+	copy(label[24:], "LVM2 001")
+
+	_, found := probeLVM2(prober{bytes.NewReader(img)})
+	c.Check(found, Equals, false)
+}
+
+func (s *ProbeTestSuite) TestProbeISO9660(c *C) {
+	info := probeFixture(c, 0x8800, func(img []byte) {
+		pvd := img[0x8000:]
+		pvd[0] = 1
+		copy(pvd[1:], "CD001")
+		copy(pvd[40:], "Ubuntu 24.04 LTS amd64          ")
+		binary.LittleEndian.PutUint32(pvd[80:], 1536000)
+		binary.LittleEndian.PutUint16(pvd[128:], 2048)
+		copy(pvd[813:], "2024042515432100\x00")
+		copy(pvd[830:], "0000000000000000\x00")
+	})
+
+	c.Check(info, DeepEquals, ProbeInfo{
+		Type:      ProbeISO9660,
+		UUID:      "2024-04-25-15-43-21-00",
+		Label:     "Ubuntu 24.04 LTS amd64",
+		Size:      1536000 * 2048,
+		BlockSize: 2048,
+	})
+}
+
+func (s *ProbeTestSuite) TestProbeSquashFS(c *C) {
+	info := probeFixture(c, 4096, func(img []byte) {
+		copy(img, "hsqs")
+		binary.LittleEndian.PutUint32(img[12:], 131072)
+		binary.LittleEndian.PutUint16(img[28:], 4)
+		binary.LittleEndian.PutUint64(img[40:], 52428800)
+	})
+
+	c.Check(info, DeepEquals, ProbeInfo{
+		Type:      ProbeSquashFS,
+		Version:   "4.0",
+		Size:      52428800,
+		BlockSize: 131072,
+	})
+}
+
+func (s *ProbeTestSuite) TestProbeUnknown(c *C) {
+	_, err := ProbeReader(bytes.NewReader(make([]byte, 1<<20)))
+	c.Check(err, Equals, ErrUnknownFileSystem)
+
+	// a partition table is not a filesystem
+	img := make([]byte, 4096)
+	writeMBREntry(img, 0, 0, 0x80, 0x83, 2048, 1024)
+
+	_, err = ProbeReader(bytes.NewReader(img))
+	c.Check(err, Equals, ErrUnknownFileSystem)
+
+	_, err = ProbeReader(bytes.NewReader(nil))
+	c.Check(err, Equals, ErrUnknownFileSystem)
+}
@@ -0,0 +1,211 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	resolvConfPath         = "/etc/resolv.conf"
+	resolvedUpstreamPath   = "/run/systemd/resolve/resolv.conf"
+	resolvedStubAddr       = "127.0.0.53"
+	resolvedStubAddrNoEDNS = "127.0.0.54"
+)
+
+// ----
+
+type ResolverOptions struct {
+	Ndots    int
+	Timeout  int
+	Attempts int
+	Rotate   bool
+	EDNS0    bool
+
+	// Every option not listed above, as written in resolv.conf
+	Other []string
+}
+
+type ResolverConfig struct {
+	Nameservers []string
+	Search      []string
+	Domain      string
+	Options     ResolverOptions
+
+	// Set when resolv.conf points to the systemd-resolved stub listener
+	StubResolver bool
+
+	// The servers queries are eventually sent to. Same as Nameservers unless
+	// StubResolver is set
+	UpstreamNameservers []string
+
+	// Set when "search" appears after "domain" in resolv.conf
+	searchLast bool
+}
+
+// Returns the domains appended to bare names. Like glibc, "domain" and
+// "search" override each other and the last one in resolv.conf wins
+func (rc ResolverConfig) SearchDomains() []string {
+	if rc.searchLast || rc.Domain == "" {
+		if len(rc.Search) <= 0 {
+			return []string(nil)
+		}
+
+		return rc.Search
+	}
+
+	return []string{rc.Domain}
+}
+
+// ----
+
+func Resolver() (ResolverConfig, error) {
+	buff, err := getResolvConf(resolvConfPath)
+	if err != nil {
+		return ResolverConfig{}, err
+	}
+
+	rc := processResolvConf(buff)
+	rc.UpstreamNameservers = rc.Nameservers
+
+	if !isStubResolver(rc) {
+		return rc, nil
+	}
+
+	rc.StubResolver = true
+
+	buff, err = getResolvConf(resolvedUpstreamPath)
+	if os.IsNotExist(err) {
+		// systemd-resolved is not running, the stub is all we know about
+		return rc, nil
+	}
+	if err != nil {
+		return rc, err
+	}
+
+	rc.UpstreamNameservers = processResolvConf(buff).Nameservers
+
+	return rc, nil
+}
+
+// ----
+
+func isStubResolver(rc ResolverConfig) bool {
+	if len(rc.Nameservers) <= 0 {
+		return false
+	}
+
+	for _, ns := range rc.Nameservers {
+		if ns != resolvedStubAddr && ns != resolvedStubAddrNoEDNS {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Used by Domain() when the host name is not fully qualified. A missing or
+// unreadable resolv.conf means there is no domain to be found
+func resolverDomain(path string) (string, error) {
+	buff, err := getResolvConf(path)
+	if err != nil {
+		return "", ErrDomainNameNotFound
+	}
+
+	domains := processResolvConf(buff).SearchDomains()
+	if len(domains) <= 0 {
+		return "", ErrDomainNameNotFound
+	}
+
+	return domains[0], nil
+}
+
+func processResolvConf(buff string) ResolverConfig {
+	// defaults from resolv.conf(5)
+	rc := ResolverConfig{
+		Options: ResolverOptions{
+			Ndots:    1,
+			Timeout:  5,
+			Attempts: 2,
+		},
+	}
+
+	for _, line := range strings.Split(buff, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) <= 0 || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "nameserver":
+			rc.Nameservers = append(rc.Nameservers, fields[1])
+		case "domain":
+			rc.Domain = fields[1]
+			rc.searchLast = false
+		case "search":
+			rc.searchLast = true
+			rc.Search = []string(nil)
+			for _, d := range fields[1:] {
+				// "search ." disables the search list
+				if d != "." {
+					rc.Search = append(rc.Search, d)
+				}
+			}
+		case "options":
+			for _, opt := range fields[1:] {
+				processResolverOption(&rc.Options, opt)
+			}
+		}
+	}
+
+	return rc
+}
+
+func processResolverOption(opts *ResolverOptions, opt string) {
+	k := opt
+	v := ""
+
+	pos := strings.Index(opt, ":")
+	if pos > -1 {
+		k = opt[:pos]
+		v = opt[pos+1:]
+	}
+
+	switch k {
+	case "ndots":
+		opts.Ndots = resolverIntOption(v, opts.Ndots)
+	case "timeout":
+		opts.Timeout = resolverIntOption(v, opts.Timeout)
+	case "attempts":
+		opts.Attempts = resolverIntOption(v, opts.Attempts)
+	case "rotate":
+		opts.Rotate = true
+	case "edns0":
+		opts.EDNS0 = true
+	default:
+		opts.Other = append(opts.Other, opt)
+	}
+}
+
+// Like the resolver, invalid values are ignored
+func resolverIntOption(v string, current int) int {
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return current
+	}
+
+	return i
+}
+
+// ----
+
+func getResolvConf(path string) (string, error) {
+	return readFile(path)
+}
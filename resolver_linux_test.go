@@ -0,0 +1,78 @@
+package libsysinfo
+
+import (
+	. "launchpad.net/gocheck"
+)
+
+type ResolverTestSuite struct{}
+
+var (
+	_ = Suite(&ResolverTestSuite{})
+)
+
+func (s *ResolverTestSuite) TestProcessResolvConf(c *C) {
+	fixture := `# Generated by NetworkManager
+; legacy comment
+domain vagrantup.com
+search vagrantup.com example.com
+nameserver 10.0.2.3
+nameserver fe80::1%eth0
+options ndots:2 timeout:1 attempts:bogus rotate edns0 single-request
+`
+	obtained := processResolvConf(fixture)
+
+	expected := ResolverConfig{
+		Nameservers: []string{"10.0.2.3", "fe80::1%eth0"},
+		Search:      []string{"vagrantup.com", "example.com"},
+		Domain:      "vagrantup.com",
+		Options: ResolverOptions{
+			Ndots:    2,
+			Timeout:  1,
+			Attempts: 2,
+			Rotate:   true,
+			EDNS0:    true,
+			Other:    []string{"single-request"},
+		},
+		searchLast: true,
+	}
+
+	c.Assert(obtained, DeepEquals, expected)
+	c.Assert(isStubResolver(obtained), Equals, false)
+}
+
+func (s *ResolverTestSuite) TestProcessResolvConf_Defaults(c *C) {
+	obtained := processResolvConf("")
+
+	c.Assert(obtained.Options.Ndots, Equals, 1)
+	c.Assert(obtained.Options.Timeout, Equals, 5)
+	c.Assert(obtained.Options.Attempts, Equals, 2)
+	c.Assert(obtained.SearchDomains(), IsNil)
+}
+
+func (s *ResolverTestSuite) TestSearchDomains(c *C) {
+	rc := processResolvConf("domain vagrantup.com\n")
+	c.Assert(rc.SearchDomains(), DeepEquals, []string{"vagrantup.com"})
+
+	rc = processResolvConf("domain vagrantup.com\nsearch example.com\n")
+	c.Assert(rc.SearchDomains(), DeepEquals, []string{"example.com"})
+
+	// the last of "domain" and "search" wins
+	rc = processResolvConf("search example.com example.org\ndomain vagrantup.com\n")
+	c.Assert(rc.SearchDomains(), DeepEquals, []string{"vagrantup.com"})
+
+	rc = processResolvConf("domain vagrantup.com\nsearch .\n")
+	c.Assert(rc.SearchDomains(), IsNil)
+}
+
+func (s *ResolverTestSuite) TestIsStubResolver(c *C) {
+	fixture := `# This is /run/systemd/resolve/stub-resolv.conf managed by man:systemd-resolved(8).
+nameserver 127.0.0.53
+options edns0 trust-ad
+search .
+`
+	obtained := processResolvConf(fixture)
+
+	c.Assert(isStubResolver(obtained), Equals, true)
+	c.Assert(obtained.Options.Other, DeepEquals, []string{"trust-ad"})
+	c.Assert(obtained.SearchDomains(), IsNil)
+}
@@ -0,0 +1,249 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"net"
+	"os"
+	"strings"
+)
+
+const (
+	FamilyIPv4 = 4
+	FamilyIPv6 = 6
+)
+
+// Route flags, see include/uapi/linux/route.h
+const (
+	RouteUp        RouteFlags = 0x0001
+	RouteGateway   RouteFlags = 0x0002
+	RouteHost      RouteFlags = 0x0004
+	RouteReinstate RouteFlags = 0x0008
+	RouteDynamic   RouteFlags = 0x0010
+	RouteModified  RouteFlags = 0x0020
+	RouteReject    RouteFlags = 0x0200
+)
+
+var (
+	ErrNoDefaultGateway = &LibSysInfoErr{"No default gateway found"}
+	ErrNoRouteFound     = &LibSysInfoErr{"No route found"}
+	ErrInvalidIP        = &LibSysInfoErr{"Invalid IP address"}
+)
+
+// ----
+
+type RouteFlags uint32
+
+func (f RouteFlags) Has(flag RouteFlags) bool {
+	return f&flag == flag
+}
+
+type Route struct {
+	// Either FamilyIPv4 or FamilyIPv6
+	Family      int
+	Interface   string
+	Destination net.IP
+	Gateway     net.IP
+	Mask        net.IPMask
+	Flags       RouteFlags
+	Metric      int
+
+	// Only available for IPv4 routes
+	MTU    int
+	Window int
+	IRTT   int
+}
+
+// Returns the length of the route's prefix in bits
+func (r Route) PrefixLen() int {
+	ones, _ := r.Mask.Size()
+	return ones
+}
+
+// ----
+
+func Routes() ([]Route, error) {
+	buff, err := getIPv4Routes()
+	if err != nil {
+		return []Route(nil), err
+	}
+
+	routes := processIPv4Routes(buff)
+
+	buff, err = getIPv6Routes()
+	if err != nil {
+		return routes, err
+	}
+
+	return append(routes, processIPv6Routes(buff)...), nil
+}
+
+// Returns the default route with the lowest metric for the given family
+func DefaultGateway(family int) (Route, error) {
+	routes, err := Routes()
+	if err != nil {
+		return Route{}, err
+	}
+
+	return findDefaultGateway(routes, family)
+}
+
+// Returns the route the kernel would most likely pick to reach ip, using the
+// longest prefix match and the lowest metric to break ties
+func RouteTo(ip net.IP) (Route, error) {
+	if ip == nil {
+		return Route{}, ErrInvalidIP
+	}
+
+	routes, err := Routes()
+	if err != nil {
+		return Route{}, err
+	}
+
+	return findRouteTo(routes, ip)
+}
+
+// ----
+
+func ipFamily(ip net.IP) int {
+	if ip.To4() != nil {
+		return FamilyIPv4
+	}
+
+	return FamilyIPv6
+}
+
+func findDefaultGateway(routes []Route, family int) (Route, error) {
+	var best Route
+	found := false
+
+	for _, r := range routes {
+		if r.Family != family || r.PrefixLen() != 0 {
+			continue
+		}
+
+		if !r.Flags.Has(RouteUp|RouteGateway) || r.Flags.Has(RouteReject) {
+			continue
+		}
+
+		if !found || r.Metric < best.Metric {
+			best = r
+			found = true
+		}
+	}
+
+	if !found {
+		return best, ErrNoDefaultGateway
+	}
+
+	return best, nil
+}
+
+func findRouteTo(routes []Route, ip net.IP) (Route, error) {
+	var best Route
+	found := false
+	family := ipFamily(ip)
+
+	for _, r := range routes {
+		if r.Family != family {
+			continue
+		}
+
+		if !r.Flags.Has(RouteUp) || r.Flags.Has(RouteReject) {
+			continue
+		}
+
+		dst := net.IPNet{IP: r.Destination, Mask: r.Mask}
+		if !dst.Contains(ip) {
+			continue
+		}
+
+		if !found || r.PrefixLen() > best.PrefixLen() {
+			best = r
+			found = true
+			continue
+		}
+
+		if r.PrefixLen() == best.PrefixLen() && r.Metric < best.Metric {
+			best = r
+		}
+	}
+
+	if !found {
+		return best, ErrNoRouteFound
+	}
+
+	return best, nil
+}
+
+func processIPv4Routes(buff string) []Route {
+	var routes []Route
+
+	for i, line := range strings.Split(buff, "\n") {
+		// first line holds the column names
+		if i == 0 {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 11 {
+			continue
+		}
+
+		routes = append(routes, Route{
+			Family:      FamilyIPv4,
+			Interface:   fields[0],
+			Destination: hextoipv4(fields[1]),
+			Gateway:     hextoipv4(fields[2]),
+			Flags:       RouteFlags(hextoui64(fields[3])),
+			Metric:      atoi(fields[6]),
+			Mask:        net.IPMask(hextoipv4(fields[7])),
+			MTU:         atoi(fields[8]),
+			Window:      atoi(fields[9]),
+			IRTT:        atoi(fields[10]),
+		})
+	}
+
+	return routes
+}
+
+func processIPv6Routes(buff string) []Route {
+	var routes []Route
+
+	for _, line := range strings.Split(buff, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 10 {
+			continue
+		}
+
+		prefixLen := int(hextoui64(fields[1]))
+
+		routes = append(routes, Route{
+			Family:      FamilyIPv6,
+			Interface:   fields[9],
+			Destination: hextoipv6(fields[0]),
+			Gateway:     hextoipv6(fields[4]),
+			Mask:        net.CIDRMask(prefixLen, 8*net.IPv6len),
+			Metric:      int(hextoui64(fields[5])),
+			Flags:       RouteFlags(hextoui64(fields[8])),
+		})
+	}
+
+	return routes
+}
+
+// ----
+
+func getIPv4Routes() (string, error) {
+	return readFile("/proc/net/route")
+}
+
+func getIPv6Routes() (string, error) {
+	buff, err := readFile("/proc/net/ipv6_route")
+	if os.IsNotExist(err) {
+		// IPv6 is disabled on this host
+		return "", nil
+	}
+
+	return buff, err
+}
@@ -0,0 +1,129 @@
+package libsysinfo
+
+import (
+	. "launchpad.net/gocheck"
+	"net"
+)
+
+type RoutesTestSuite struct{}
+
+var (
+	_ = Suite(&RoutesTestSuite{})
+
+	ipv4RoutesFixture = `Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+eth0	00000000	0102000A	0003	0	0	100	00000000	0	0	0
+eth0	0002000A	00000000	0001	0	0	0	00FFFFFF	0	0	0
+wlan0	00000000	0101A8C0	0003	0	0	600	00000000	0	0	0
+wlan0	0001A8C0	00000000	0001	0	0	600	00FFFFFF	1500	0	0
+tun0	0080A8C0	00000000	0001	0	0	0	0080FFFF	0	0	0
+`
+
+	ipv6RoutesFixture = `fe800000000000000000000000000000 40 00000000000000000000000000000000 00 00000000000000000000000000000000 00000100 00000001 00000000 00000001     eth0
+00000000000000000000000000000000 00 00000000000000000000000000000000 00 fe800000000000000000000000000001 00000400 00000001 00000000 00000003     eth0
+00000000000000000000000000000001 80 00000000000000000000000000000000 00 00000000000000000000000000000000 00000000 00000002 00000000 80200001       lo
+00000000000000000000000000000000 00 00000000000000000000000000000000 00 00000000000000000000000000000000 ffffffff 00000001 00000000 00200200       lo
+`
+)
+
+func (s *RoutesTestSuite) TestProcessIPv4Routes(c *C) {
+	obtained := processIPv4Routes(ipv4RoutesFixture)
+
+	c.Assert(len(obtained), Equals, 5)
+
+	c.Assert(obtained[0], DeepEquals, Route{
+		Family:      FamilyIPv4,
+		Interface:   "eth0",
+		Destination: net.IP{0, 0, 0, 0},
+		Gateway:     net.IP{10, 0, 2, 1},
+		Mask:        net.IPMask{0, 0, 0, 0},
+		Flags:       RouteUp | RouteGateway,
+		Metric:      100,
+	})
+
+	c.Assert(obtained[3], DeepEquals, Route{
+		Family:      FamilyIPv4,
+		Interface:   "wlan0",
+		Destination: net.IP{192, 168, 1, 0},
+		Gateway:     net.IP{0, 0, 0, 0},
+		Mask:        net.IPMask{255, 255, 255, 0},
+		Flags:       RouteUp,
+		Metric:      600,
+		MTU:         1500,
+	})
+
+	c.Assert(obtained[4].PrefixLen(), Equals, 17)
+}
+
+func (s *RoutesTestSuite) TestProcessIPv6Routes(c *C) {
+	obtained := processIPv6Routes(ipv6RoutesFixture)
+
+	c.Assert(len(obtained), Equals, 4)
+
+	c.Assert(obtained[1], DeepEquals, Route{
+		Family:      FamilyIPv6,
+		Interface:   "eth0",
+		Destination: net.ParseIP("::"),
+		Gateway:     net.ParseIP("fe80::1"),
+		Mask:        net.CIDRMask(0, 128),
+		Flags:       RouteUp | RouteGateway,
+		Metric:      1024,
+	})
+
+	c.Assert(obtained[2].Destination.String(), Equals, "::1")
+	c.Assert(obtained[2].PrefixLen(), Equals, 128)
+	c.Assert(obtained[3].Flags.Has(RouteReject), Equals, true)
+}
+
+func (s *RoutesTestSuite) TestFindDefaultGateway(c *C) {
+	routes := append(
+		processIPv4Routes(ipv4RoutesFixture),
+		processIPv6Routes(ipv6RoutesFixture)...,
+	)
+
+	r, err := findDefaultGateway(routes, FamilyIPv4)
+	c.Assert(err, IsNil)
+	c.Assert(r.Interface, Equals, "eth0")
+	c.Assert(r.Gateway.String(), Equals, "10.0.2.1")
+
+	r, err = findDefaultGateway(routes, FamilyIPv6)
+	c.Assert(err, IsNil)
+	c.Assert(r.Interface, Equals, "eth0")
+	c.Assert(r.Gateway.String(), Equals, "fe80::1")
+}
+
+func (s *RoutesTestSuite) TestFindDefaultGateway_NotFound(c *C) {
+	routes := processIPv4Routes(ipv4RoutesFixture)
+
+	_, err := findDefaultGateway(routes, FamilyIPv6)
+	c.Assert(err, Equals, ErrNoDefaultGateway)
+}
+
+func (s *RoutesTestSuite) TestFindRouteTo(c *C) {
+	routes := append(
+		processIPv4Routes(ipv4RoutesFixture),
+		processIPv6Routes(ipv6RoutesFixture)...,
+	)
+
+	expected := map[string]string{
+		"192.168.1.42":  "wlan0",
+		"192.168.200.1": "tun0",
+		"10.0.2.15":     "eth0",
+		"8.8.8.8":       "eth0",
+		"fe80::42":      "eth0",
+		"::1":           "lo",
+		"2001:db8::1":   "eth0",
+	}
+
+	for ip, iface := range expected {
+		r, err := findRouteTo(routes, net.ParseIP(ip))
+		c.Assert(err, IsNil)
+		c.Assert(r.Interface, Equals, iface, Commentf("ip: %s", ip))
+	}
+}
+
+func (s *RoutesTestSuite) TestFindRouteTo_NotFound(c *C) {
+	routes := processIPv6Routes(ipv6RoutesFixture)
+
+	_, err := findRouteTo(routes, net.ParseIP("10.0.2.15"))
+	c.Assert(err, Equals, ErrNoRouteFound)
+}
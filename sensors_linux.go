@@ -0,0 +1,384 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Degrees Celsius
+	SensorTemperature = "temperature"
+
+	// Revolutions per minute
+	SensorFan = "fan"
+
+	// Volts
+	SensorVoltage = "voltage"
+
+	// Watts
+	SensorPower = "power"
+
+	// Amperes
+	SensorCurrent = "current"
+)
+
+const (
+	sysHwmonPath   = "/sys/class/hwmon"
+	sysThermalPath = "/sys/class/thermal"
+)
+
+var (
+	// e.g. temp1_input, in0_max or fan2_alarm
+	hwmonAttrRegexp = regexp.MustCompile(`^(temp|fan|in|power|curr)(\d+)_(\w+)$`)
+
+	// Order in which the sensors of a chip are returned
+	hwmonSensorOrder = map[string]int{
+		"temp":  0,
+		"fan":   1,
+		"in":    2,
+		"power": 3,
+		"curr":  4,
+	}
+
+	hwmonSensorTypes = map[string]string{
+		"temp":  SensorTemperature,
+		"fan":   SensorFan,
+		"in":    SensorVoltage,
+		"power": SensorPower,
+		"curr":  SensorCurrent,
+	}
+
+	// Divisors turning the raw sysfs values into SI units
+	hwmonScales = map[string]float64{
+		"temp":  1000,
+		"fan":   1,
+		"in":    1000,
+		"power": 1000000,
+		"curr":  1000,
+	}
+)
+
+// ----
+
+// A hardware monitoring chip, e.g. coretemp, k10temp, nct6775 or nvme
+type SensorChip struct {
+	// e.g. hwmon0
+	Name string
+
+	// Driver name of the chip
+	Chip string
+
+	Sensors []Sensor
+}
+
+// A sensor reading. Values are in SI units, see the Sensor* constants.
+// Thresholds are zero when the chip does not report them.
+type Sensor struct {
+	// One of the Sensor* constants
+	Type string
+
+	// e.g. temp1 or fan2
+	Name string
+
+	// e.g. "Package id 0" or "Composite", empty when not provided
+	Label string
+
+	Value float64
+
+	Min     float64
+	Max     float64
+	Crit    float64
+	LowCrit float64
+
+	Alarm     bool
+	CritAlarm bool
+}
+
+type ThermalZone struct {
+	// e.g. thermal_zone0
+	Name string
+
+	// e.g. x86_pkg_temp, acpitz or cpu-thermal
+	Type string
+
+	// In degrees Celsius
+	Temperature float64
+
+	// enabled or disabled, empty on kernels without the mode attribute
+	Mode string
+
+	// e.g. step_wise
+	Policy string
+
+	TripPoints []TripPoint
+
+	// Cooling devices bound to the zone
+	CoolingDevices []CoolingDevice
+}
+
+// Temperatures are in degrees Celsius
+type TripPoint struct {
+	// active, passive, hot or critical
+	Type string
+
+	Temperature float64
+	Hysteresis  float64
+}
+
+// A device able to lower a temperature, e.g. a fan or a CPU frequency
+// limiter. A CurState above 0 means the device is throttling.
+type CoolingDevice struct {
+	// e.g. cooling_device0
+	Name string
+
+	// e.g. Processor, intel_powerclamp or Fan
+	Type string
+
+	CurState int
+	MaxState int
+
+	// Index of the trip point of the zone activating the device, -1 when
+	// not bound to a zone
+	TripPoint int
+}
+
+// ----
+
+func Sensors() ([]SensorChip, error) {
+	_, err := os.Stat(sysHwmonPath)
+	if err != nil {
+		return []SensorChip(nil), err
+	}
+
+	return readSensorChips(sysHwmonPath), nil
+}
+
+func ThermalZones() ([]ThermalZone, error) {
+	_, err := os.Stat(sysThermalPath)
+	if err != nil {
+		return []ThermalZone(nil), err
+	}
+
+	return readThermalZones(sysThermalPath), nil
+}
+
+// Returns every cooling device, bound to a thermal zone or not
+func CoolingDevices() ([]CoolingDevice, error) {
+	_, err := os.Stat(sysThermalPath)
+	if err != nil {
+		return []CoolingDevice(nil), err
+	}
+
+	return readCoolingDevices(sysThermalPath), nil
+}
+
+// ----
+
+func readSensorChips(root string) []SensorChip {
+	var chips []SensorChip
+
+	for _, name := range sortedIndexedNames(root, "hwmon") {
+		dir := filepath.Join(root, name)
+
+		// kernels older than 3.15 expose the attributes of some drivers in
+		// the device directory
+		if sysfsString(filepath.Join(dir, "name")) == "" {
+			dir = filepath.Join(dir, "device")
+		}
+
+		chips = append(chips, SensorChip{
+			Name:    name,
+			Chip:    sysfsString(filepath.Join(dir, "name")),
+			Sensors: readSensors(dir),
+		})
+	}
+
+	return chips
+}
+
+func readSensors(dir string) []Sensor {
+	var sensors []Sensor
+
+	type sensorKey struct {
+		kind  string
+		index int
+	}
+
+	attrs := make(map[sensorKey]map[string]string)
+	var keys []sensorKey
+
+	for _, name := range dirNames(dir) {
+		m := hwmonAttrRegexp.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+
+		k := sensorKey{kind: m[1], index: atoi(m[2])}
+		if attrs[k] == nil {
+			attrs[k] = make(map[string]string)
+			keys = append(keys, k)
+		}
+
+		attrs[k][m[3]] = sysfsString(filepath.Join(dir, name))
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].kind != keys[j].kind {
+			return hwmonSensorOrder[keys[i].kind] < hwmonSensorOrder[keys[j].kind]
+		}
+
+		return keys[i].index < keys[j].index
+	})
+
+	for _, k := range keys {
+		a := attrs[k]
+
+		input, exists := a["input"]
+		if !exists && k.kind == "power" {
+			// most power meters only report an average
+			input, exists = a["average"]
+		}
+		if !exists {
+			continue
+		}
+
+		scale := hwmonScales[k.kind]
+		value := func(attr string) float64 {
+			v, err := strconv.ParseFloat(a[attr], 64)
+			if err != nil {
+				return 0
+			}
+
+			return v / scale
+		}
+
+		s := Sensor{
+			Type:      hwmonSensorTypes[k.kind],
+			Name:      k.kind + strconv.Itoa(k.index),
+			Label:     a["label"],
+			Min:       value("min"),
+			Max:       value("max"),
+			Crit:      value("crit"),
+			LowCrit:   value("lcrit"),
+			Alarm:     a["alarm"] == "1" || a["max_alarm"] == "1" || a["min_alarm"] == "1",
+			CritAlarm: a["crit_alarm"] == "1" || a["lcrit_alarm"] == "1",
+		}
+
+		v, err := strconv.ParseFloat(input, 64)
+		if err == nil {
+			s.Value = v / scale
+		}
+
+		if s.Max == 0 && k.kind == "power" {
+			s.Max = value("cap")
+		}
+
+		sensors = append(sensors, s)
+	}
+
+	return sensors
+}
+
+// Returns the entries of dir named prefix<N>, sorted by N
+func sortedIndexedNames(dir string, prefix string) []string {
+	var names []string
+
+	for _, name := range dirNames(dir) {
+		_, err := strconv.Atoi(strings.TrimPrefix(name, prefix))
+		if strings.HasPrefix(name, prefix) && err == nil {
+			names = append(names, name)
+		}
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		a, _ := strconv.Atoi(strings.TrimPrefix(names[i], prefix))
+		b, _ := strconv.Atoi(strings.TrimPrefix(names[j], prefix))
+		return a < b
+	})
+
+	return names
+}
+
+func readThermalZones(root string) []ThermalZone {
+	var zones []ThermalZone
+
+	for _, name := range sortedIndexedNames(root, "thermal_zone") {
+		zones = append(zones, readThermalZone(root, name))
+	}
+
+	return zones
+}
+
+func readThermalZone(root string, name string) ThermalZone {
+	dir := filepath.Join(root, name)
+
+	tz := ThermalZone{
+		Name:        name,
+		Type:        sysfsString(filepath.Join(dir, "type")),
+		Temperature: float64(sysfsInt(filepath.Join(dir, "temp"))) / 1000,
+		Mode:        sysfsString(filepath.Join(dir, "mode")),
+		Policy:      sysfsString(filepath.Join(dir, "policy")),
+	}
+
+	// trip points are numbered from 0 without gaps
+	for i := 0; ; i++ {
+		prefix := filepath.Join(dir, "trip_point_"+strconv.Itoa(i)+"_")
+
+		t := sysfsString(prefix + "type")
+		if t == "" {
+			break
+		}
+
+		tz.TripPoints = append(tz.TripPoints, TripPoint{
+			Type:        t,
+			Temperature: float64(sysfsInt(prefix+"temp")) / 1000,
+			Hysteresis:  float64(sysfsInt(prefix+"hyst")) / 1000,
+		})
+	}
+
+	for _, cdev := range sortedIndexedNames(dir, "cdev") {
+		target, err := os.Readlink(filepath.Join(dir, cdev))
+		if err != nil {
+			continue
+		}
+
+		cd := readCoolingDevice(root, filepath.Base(target))
+
+		trip, err := strconv.Atoi(sysfsString(filepath.Join(dir, cdev+"_trip_point")))
+		if err == nil {
+			cd.TripPoint = trip
+		}
+
+		tz.CoolingDevices = append(tz.CoolingDevices, cd)
+	}
+
+	return tz
+}
+
+func readCoolingDevices(root string) []CoolingDevice {
+	var devices []CoolingDevice
+
+	for _, name := range sortedIndexedNames(root, "cooling_device") {
+		devices = append(devices, readCoolingDevice(root, name))
+	}
+
+	return devices
+}
+
+func readCoolingDevice(root string, name string) CoolingDevice {
+	dir := filepath.Join(root, name)
+
+	return CoolingDevice{
+		Name:      name,
+		Type:      sysfsString(filepath.Join(dir, "type")),
+		CurState:  sysfsInt(filepath.Join(dir, "cur_state")),
+		MaxState:  sysfsInt(filepath.Join(dir, "max_state")),
+		TripPoint: -1,
+	}
+}
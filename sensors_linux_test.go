@@ -0,0 +1,133 @@
+package libsysinfo
+
+import (
+	"os"
+	"path/filepath"
+
+	. "launchpad.net/gocheck"
+)
+
+type SensorsTestSuite struct{}
+
+var (
+	_ = Suite(&SensorsTestSuite{})
+)
+
+func (s *SensorsTestSuite) TestReadSensorChips(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"hwmon0/name":              "coretemp\n",
+		"hwmon0/temp1_input":       "52000\n",
+		"hwmon0/temp1_label":       "Package id 0\n",
+		"hwmon0/temp1_max":         "80000\n",
+		"hwmon0/temp1_crit":        "100000\n",
+		"hwmon0/temp1_crit_alarm":  "0\n",
+		"hwmon0/temp2_input":       "-5500\n",
+		"hwmon0/temp2_max_alarm":   "1\n",
+		"hwmon10/name":             "nct6775\n",
+		"hwmon10/fan1_input":       "1250\n",
+		"hwmon10/fan1_min":         "300\n",
+		"hwmon10/fan1_alarm":       "0\n",
+		"hwmon10/in0_input":        "1184\n",
+		"hwmon10/in0_min":          "1000\n",
+		"hwmon10/in0_max":          "1500\n",
+		"hwmon10/curr1_input":      "2500\n",
+		"hwmon10/power1_average":   "45250000\n",
+		"hwmon10/power1_cap":       "65000000\n",
+		"hwmon10/temp7_max":        "90000\n",
+		"hwmon2/device/name":       "it87\n",
+		"hwmon2/device/fan2_input": "900\n",
+	})
+
+	obtained := readSensorChips(root)
+
+	expected := []SensorChip{
+		SensorChip{
+			Name: "hwmon0",
+			Chip: "coretemp",
+			Sensors: []Sensor{
+				Sensor{Type: SensorTemperature, Name: "temp1", Label: "Package id 0", Value: 52, Max: 80, Crit: 100},
+				Sensor{Type: SensorTemperature, Name: "temp2", Value: -5.5, Alarm: true},
+			},
+		},
+		SensorChip{
+			Name: "hwmon2",
+			Chip: "it87",
+			Sensors: []Sensor{
+				Sensor{Type: SensorFan, Name: "fan2", Value: 900},
+			},
+		},
+		SensorChip{
+			Name: "hwmon10",
+			Chip: "nct6775",
+			Sensors: []Sensor{
+				Sensor{Type: SensorFan, Name: "fan1", Value: 1250, Min: 300},
+				Sensor{Type: SensorVoltage, Name: "in0", Value: 1.184, Min: 1, Max: 1.5},
+				Sensor{Type: SensorPower, Name: "power1", Value: 45.25, Max: 65},
+				Sensor{Type: SensorCurrent, Name: "curr1", Value: 2.5},
+			},
+		},
+	}
+
+	c.Assert(obtained, DeepEquals, expected)
+}
+
+func (s *SensorsTestSuite) TestReadThermalZones(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"thermal_zone0/type":              "x86_pkg_temp\n",
+		"thermal_zone0/temp":              "87500\n",
+		"thermal_zone0/mode":              "enabled\n",
+		"thermal_zone0/policy":            "step_wise\n",
+		"thermal_zone0/trip_point_0_type": "passive\n",
+		"thermal_zone0/trip_point_0_temp": "85000\n",
+		"thermal_zone0/trip_point_0_hyst": "2000\n",
+		"thermal_zone0/trip_point_1_type": "critical\n",
+		"thermal_zone0/trip_point_1_temp": "105000\n",
+		"thermal_zone0/cdev0_trip_point":  "0\n",
+		"thermal_zone1/type":              "acpitz\n",
+		"thermal_zone1/temp":              "27800\n",
+		"cooling_device0/type":            "Processor\n",
+		"cooling_device0/cur_state":       "3\n",
+		"cooling_device0/max_state":       "10\n",
+		"cooling_device1/type":            "Fan\n",
+		"cooling_device1/cur_state":       "0\n",
+		"cooling_device1/max_state":       "1\n",
+	})
+
+	err := os.Symlink("../cooling_device0", filepath.Join(root, "thermal_zone0", "cdev0"))
+	c.Assert(err, IsNil)
+
+	obtained := readThermalZones(root)
+
+	expected := []ThermalZone{
+		ThermalZone{
+			Name:        "thermal_zone0",
+			Type:        "x86_pkg_temp",
+			Temperature: 87.5,
+			Mode:        "enabled",
+			Policy:      "step_wise",
+			TripPoints: []TripPoint{
+				TripPoint{Type: "passive", Temperature: 85, Hysteresis: 2},
+				TripPoint{Type: "critical", Temperature: 105},
+			},
+			CoolingDevices: []CoolingDevice{
+				CoolingDevice{Name: "cooling_device0", Type: "Processor", CurState: 3, MaxState: 10, TripPoint: 0},
+			},
+		},
+		ThermalZone{
+			Name:        "thermal_zone1",
+			Type:        "acpitz",
+			Temperature: 27.8,
+		},
+	}
+
+	c.Assert(obtained, DeepEquals, expected)
+
+	c.Assert(readCoolingDevices(root), DeepEquals, []CoolingDevice{
+		CoolingDevice{Name: "cooling_device0", Type: "Processor", CurState: 3, MaxState: 10, TripPoint: -1},
+		CoolingDevice{Name: "cooling_device1", Type: "Fan", CurState: 0, MaxState: 1, TripPoint: -1},
+	})
+}
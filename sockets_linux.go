@@ -0,0 +1,408 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Socket states, shared by TCP, UDP and raw sockets. See
+// include/net/tcp_states.h
+const (
+	SocketEstablished SocketState = iota + 1
+	SocketSynSent
+	SocketSynRecv
+	SocketFinWait1
+	SocketFinWait2
+	SocketTimeWait
+	SocketClose
+	SocketCloseWait
+	SocketLastAck
+	SocketListen
+	SocketClosing
+	SocketNewSynRecv
+)
+
+// Unix socket types, see include/linux/net.h
+const (
+	UnixStream    = 1
+	UnixDgram     = 2
+	UnixSeqPacket = 5
+)
+
+const (
+	// __SO_ACCEPTCON, set on listening unix sockets
+	unixAcceptCon = 0x10000
+
+	unixUnconnected   = 1
+	unixConnecting    = 2
+	unixConnected     = 3
+	unixDisconnecting = 4
+)
+
+var (
+	socketTables = []string{"tcp", "tcp6", "udp", "udp6", "raw", "raw6"}
+)
+
+// ----
+
+type SocketState int
+
+func (s SocketState) String() string {
+	switch s {
+	case SocketEstablished:
+		return "established"
+	case SocketSynSent:
+		return "syn_sent"
+	case SocketSynRecv:
+		return "syn_recv"
+	case SocketFinWait1:
+		return "fin_wait1"
+	case SocketFinWait2:
+		return "fin_wait2"
+	case SocketTimeWait:
+		return "time_wait"
+	case SocketClose:
+		return "close"
+	case SocketCloseWait:
+		return "close_wait"
+	case SocketLastAck:
+		return "last_ack"
+	case SocketListen:
+		return "listen"
+	case SocketClosing:
+		return "closing"
+	case SocketNewSynRecv:
+		return "new_syn_recv"
+	}
+
+	return "unknown"
+}
+
+type Socket struct {
+	// One of tcp, tcp6, udp, udp6, raw, raw6 or unix
+	Protocol   string
+	LocalAddr  net.IP
+	LocalPort  int
+	RemoteAddr net.IP
+	RemotePort int
+	State      SocketState
+	TxQueue    int
+	RxQueue    int
+	UID        int
+	Inode      uint64
+
+	// Only available for unix sockets. Abstract socket paths start with "@"
+	Path string
+	Type int
+}
+
+type SocketOwner struct {
+	PID     int
+	Command string
+
+	// Empty when /proc/[pid]/exe is not readable, most likely because the
+	// process belongs to another user
+	Exe string
+}
+
+type ListeningPort struct {
+	Protocol string
+	Addr     net.IP
+	Port     int
+	Inode    uint64
+	Owners   []SocketOwner
+}
+
+// ----
+
+func Sockets() ([]Socket, error) {
+	var sockets []Socket
+
+	for _, proto := range socketTables {
+		buff, err := getSocketTable(proto)
+		if err != nil {
+			return sockets, err
+		}
+
+		sockets = append(sockets, processSocketTable(proto, buff)...)
+	}
+
+	buff, err := getSocketTable("unix")
+	if err != nil {
+		return sockets, err
+	}
+
+	return append(sockets, processUnixSockets(buff)...), nil
+}
+
+// Maps every socket inode to the PIDs holding a file descriptor on it.
+// Only the processes visible to the current user are reported.
+func SocketOwners() (map[uint64][]int, error) {
+	return findSocketOwners("/proc")
+}
+
+// Lists the TCP sockets in the listening state and the unconnected UDP
+// sockets bound to a port, along with the processes owning them.
+func ListeningPorts() ([]ListeningPort, error) {
+	var ports []ListeningPort
+
+	sockets, err := Sockets()
+	if err != nil {
+		return ports, err
+	}
+
+	owners, err := SocketOwners()
+	if err != nil {
+		return ports, err
+	}
+
+	for _, s := range filterListening(sockets) {
+		lp := ListeningPort{
+			Protocol: s.Protocol,
+			Addr:     s.LocalAddr,
+			Port:     s.LocalPort,
+			Inode:    s.Inode,
+		}
+
+		for _, pid := range owners[s.Inode] {
+			lp.Owners = append(lp.Owners, findSocketOwner("/proc", pid))
+		}
+
+		ports = append(ports, lp)
+	}
+
+	return ports, nil
+}
+
+// ----
+
+func filterListening(sockets []Socket) []Socket {
+	var out []Socket
+
+	for _, s := range sockets {
+		switch s.Protocol {
+		case "tcp", "tcp6":
+			if s.State == SocketListen {
+				out = append(out, s)
+			}
+		case "udp", "udp6":
+			if s.State == SocketClose && s.RemotePort == 0 && s.LocalPort != 0 {
+				out = append(out, s)
+			}
+		}
+	}
+
+	return out
+}
+
+func findSocketOwners(procRoot string) (map[uint64][]int, error) {
+	owners := make(map[uint64][]int)
+
+	f, err := os.Open(procRoot)
+	if err != nil {
+		return owners, err
+	}
+	defer f.Close()
+
+	allNames := -1
+	names, err := f.Readdirnames(allNames)
+	if err != nil {
+		return owners, err
+	}
+
+	for _, name := range names {
+		pid, err := strconv.Atoi(name)
+		if err != nil {
+			continue
+		}
+
+		fds, err := filepath.Glob(filepath.Join(procRoot, name, "fd", "*"))
+		if err != nil {
+			continue
+		}
+
+		for _, fd := range fds {
+			// processes may exit or close fds while we are looking
+			link, err := os.Readlink(fd)
+			if err != nil {
+				continue
+			}
+
+			inode, ok := parseSocketLink(link)
+			if !ok {
+				continue
+			}
+
+			if !containsInt(owners[inode], pid) {
+				owners[inode] = append(owners[inode], pid)
+			}
+		}
+	}
+
+	return owners, nil
+}
+
+func findSocketOwner(procRoot string, pid int) SocketOwner {
+	dir := filepath.Join(procRoot, strconv.Itoa(pid))
+
+	comm, _ := readFile(filepath.Join(dir, "comm"))
+	exe, _ := os.Readlink(filepath.Join(dir, "exe"))
+
+	return SocketOwner{
+		PID:     pid,
+		Command: strings.TrimSpace(comm),
+		Exe:     exe,
+	}
+}
+
+func containsInt(ints []int, i int) bool {
+	for _, v := range ints {
+		if v == i {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Extracts the inode from a /proc/[pid]/fd link such as "socket:[12345]"
+func parseSocketLink(link string) (uint64, bool) {
+	const prefix = "socket:["
+
+	if !strings.HasPrefix(link, prefix) || !strings.HasSuffix(link, "]") {
+		return 0, false
+	}
+
+	inode, err := strconv.ParseUint(link[len(prefix):len(link)-1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return inode, true
+}
+
+// Decodes an "address:port" pair from /proc/net/{tcp,udp,raw}[6]
+func parseSocketAddr(s string) (net.IP, int) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return nil, 0
+	}
+
+	port := int(hextoui64(parts[1]))
+
+	if len(parts[0]) == 8 {
+		return hextoipv4(parts[0]), port
+	}
+
+	// IPv6 addresses are printed as four 32 bits words in host byte order
+	ip := make(net.IP, 0, net.IPv6len)
+	for i := 0; i+8 <= len(parts[0]); i += 8 {
+		ip = append(ip, hextoipv4(parts[0][i:i+8])...)
+	}
+
+	return ip, port
+}
+
+func processSocketTable(proto string, buff string) []Socket {
+	var sockets []Socket
+
+	for i, line := range strings.Split(buff, "\n") {
+		// first line holds the column names
+		if i == 0 {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 10 {
+			continue
+		}
+
+		s := Socket{
+			Protocol: proto,
+			State:    SocketState(hextoui64(fields[3])),
+			UID:      atoi(fields[7]),
+		}
+
+		s.LocalAddr, s.LocalPort = parseSocketAddr(fields[1])
+		s.RemoteAddr, s.RemotePort = parseSocketAddr(fields[2])
+
+		queues := strings.Split(fields[4], ":")
+		if len(queues) == 2 {
+			s.TxQueue = int(hextoui64(queues[0]))
+			s.RxQueue = int(hextoui64(queues[1]))
+		}
+
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err == nil {
+			s.Inode = inode
+		}
+
+		sockets = append(sockets, s)
+	}
+
+	return sockets
+}
+
+func processUnixSockets(buff string) []Socket {
+	var sockets []Socket
+
+	for i, line := range strings.Split(buff, "\n") {
+		// first line holds the column names
+		if i == 0 {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 7 {
+			continue
+		}
+
+		flags := hextoui64(fields[3])
+
+		s := Socket{
+			Protocol: "unix",
+			Type:     int(hextoui64(fields[4])),
+			Path:     strings.Join(fields[7:], " "),
+		}
+
+		switch hextoui64(fields[5]) {
+		case unixUnconnected:
+			s.State = SocketClose
+			if flags&unixAcceptCon != 0 {
+				s.State = SocketListen
+			}
+		case unixConnecting:
+			s.State = SocketSynSent
+		case unixConnected:
+			s.State = SocketEstablished
+		case unixDisconnecting:
+			s.State = SocketClosing
+		}
+
+		inode, err := strconv.ParseUint(fields[6], 10, 64)
+		if err == nil {
+			s.Inode = inode
+		}
+
+		sockets = append(sockets, s)
+	}
+
+	return sockets
+}
+
+// ----
+
+func getSocketTable(proto string) (string, error) {
+	buff, err := readFile("/proc/net/" + proto)
+	if os.IsNotExist(err) {
+		// protocol not compiled in or disabled, e.g. IPv6
+		return "", nil
+	}
+
+	return buff, err
+}
@@ -0,0 +1,135 @@
+package libsysinfo
+
+import (
+	. "launchpad.net/gocheck"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+type SocketsTestSuite struct{}
+
+var (
+	_ = Suite(&SocketsTestSuite{})
+)
+
+func (s *SocketsTestSuite) TestProcessSocketTable_TCP(c *C) {
+	fixture := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 11234 1 0000000000000000 100 0 0 10 0
+   1: 0F02000A:0016 0202000A:C350 01 00000024:00000000 01:00000019 00000000     0        0 15521 4 0000000000000000 20 4 29 10 -1
+`
+	obtained := processSocketTable("tcp", fixture)
+
+	c.Assert(len(obtained), Equals, 2)
+
+	c.Assert(obtained[0], DeepEquals, Socket{
+		Protocol:   "tcp",
+		LocalAddr:  net.IP{0, 0, 0, 0},
+		LocalPort:  22,
+		RemoteAddr: net.IP{0, 0, 0, 0},
+		RemotePort: 0,
+		State:      SocketListen,
+		Inode:      11234,
+	})
+
+	c.Assert(obtained[1], DeepEquals, Socket{
+		Protocol:   "tcp",
+		LocalAddr:  net.IP{10, 0, 2, 15},
+		LocalPort:  22,
+		RemoteAddr: net.IP{10, 0, 2, 2},
+		RemotePort: 50000,
+		State:      SocketEstablished,
+		TxQueue:    36,
+		Inode:      15521,
+	})
+}
+
+func (s *SocketsTestSuite) TestProcessSocketTable_TCP6(c *C) {
+	fixture := `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000001000000:0277 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000   997        0 18077 1 0000000000000000 100 0 0 10 0
+`
+	obtained := processSocketTable("tcp6", fixture)
+
+	c.Assert(len(obtained), Equals, 1)
+	c.Assert(obtained[0].LocalAddr.String(), Equals, "::1")
+	c.Assert(obtained[0].LocalPort, Equals, 631)
+	c.Assert(obtained[0].RemoteAddr.String(), Equals, "::")
+	c.Assert(obtained[0].UID, Equals, 997)
+	c.Assert(obtained[0].State, Equals, SocketListen)
+}
+
+func (s *SocketsTestSuite) TestProcessUnixSockets(c *C) {
+	fixture := `Num       RefCount Protocol Flags    Type St Inode Path
+0000000000000000: 00000002 00000000 00010000 0001 01 13516 /run/systemd/private
+0000000000000000: 00000003 00000000 00000000 0001 03 17041
+0000000000000000: 00000002 00000000 00000000 0002 01 12001 @/org/kernel/udev/udevd
+`
+	obtained := processUnixSockets(fixture)
+
+	c.Assert(len(obtained), Equals, 3)
+
+	c.Assert(obtained[0], DeepEquals, Socket{
+		Protocol: "unix",
+		State:    SocketListen,
+		Inode:    13516,
+		Path:     "/run/systemd/private",
+		Type:     UnixStream,
+	})
+
+	c.Assert(obtained[1].State, Equals, SocketEstablished)
+	c.Assert(obtained[1].Path, Equals, "")
+	c.Assert(obtained[2].State, Equals, SocketClose)
+	c.Assert(obtained[2].Type, Equals, UnixDgram)
+	c.Assert(obtained[2].Path, Equals, "@/org/kernel/udev/udevd")
+}
+
+func (s *SocketsTestSuite) TestParseSocketLink(c *C) {
+	inode, ok := parseSocketLink("socket:[12345]")
+	c.Assert(ok, Equals, true)
+	c.Assert(inode, Equals, uint64(12345))
+
+	for _, l := range []string{"pipe:[12345]", "/dev/null", "socket:[]"} {
+		_, ok = parseSocketLink(l)
+		c.Assert(ok, Equals, false)
+	}
+}
+
+func (s *SocketsTestSuite) TestFilterListening(c *C) {
+	sockets := []Socket{
+		{Protocol: "tcp", LocalPort: 22, State: SocketListen},
+		{Protocol: "tcp", LocalPort: 22, RemotePort: 50000, State: SocketEstablished},
+		{Protocol: "udp", LocalPort: 53, State: SocketClose},
+		{Protocol: "udp", LocalPort: 40000, RemotePort: 53, State: SocketEstablished},
+		{Protocol: "unix", State: SocketListen},
+	}
+
+	obtained := filterListening(sockets)
+
+	c.Assert(len(obtained), Equals, 2)
+	c.Assert(obtained[0].LocalPort, Equals, 22)
+	c.Assert(obtained[1].LocalPort, Equals, 53)
+}
+
+func (s *SocketsTestSuite) TestFindSocketOwners(c *C) {
+	root := c.MkDir()
+
+	links := map[string]string{
+		"42/fd/0":   "/dev/null",
+		"42/fd/3":   "socket:[1000]",
+		"42/fd/4":   "socket:[1001]",
+		"43/fd/3":   "socket:[1000]",
+		"self/fd/3": "socket:[2000]",
+	}
+
+	for fd, target := range links {
+		path := filepath.Join(root, fd)
+		c.Assert(os.MkdirAll(filepath.Dir(path), 0755), IsNil)
+		c.Assert(os.Symlink(target, path), IsNil)
+	}
+
+	obtained, err := findSocketOwners(root)
+	c.Assert(err, IsNil)
+	c.Assert(len(obtained), Equals, 2)
+	c.Assert(obtained[1001], DeepEquals, []int{42})
+	c.Assert(len(obtained[1000]), Equals, 2)
+}
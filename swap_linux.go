@@ -0,0 +1,284 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	SwapPartition = "partition"
+	SwapFile      = "file"
+	SwapZram      = "zram"
+)
+
+const (
+	zswapParametersPath = "/sys/module/zswap/parameters"
+)
+
+// ----
+
+type SwapDevice struct {
+	Filename string
+
+	// One of SwapPartition, SwapFile or SwapZram
+	Type string
+
+	// In bytes
+	Size uint64
+	Used uint64
+
+	Priority int
+
+	// Only set for zram devices
+	Zram *ZramDevice
+}
+
+// A compressed RAM block device, usually used as swap. Sizes are in bytes.
+type ZramDevice struct {
+	Name string
+
+	DiskSize uint64
+
+	// The active compression algorithm
+	CompAlgorithm string
+
+	// Uncompressed size of the data stored on the device
+	OrigDataSize uint64
+
+	// Compressed size of the data stored on the device
+	ComprDataSize uint64
+
+	// Memory allocated to store the compressed data, including fragmentation
+	MemUsedTotal uint64
+
+	// Zero when no limit is set
+	MemLimit uint64
+
+	MemUsedMax uint64
+}
+
+// Compression ratio of the data stored on the device, zero when empty
+func (z ZramDevice) CompressionRatio() float64 {
+	if z.ComprDataSize == 0 {
+		return 0
+	}
+
+	return float64(z.OrigDataSize) / float64(z.ComprDataSize)
+}
+
+// Configuration of the compressed cache for swap pages
+type ZswapInfo struct {
+	// Set when the zswap module is loaded, whether enabled or not
+	Available bool
+
+	Enabled        bool
+	Compressor     string
+	Zpool          string
+	MaxPoolPercent int
+
+	// In bytes, from /proc/meminfo. Zero on kernels older than 5.19
+	CompressedSize uint64
+	OriginalSize   uint64
+}
+
+// ----
+
+func SwapDevices() ([]SwapDevice, error) {
+	buff, err := getSwaps()
+	if err != nil {
+		return []SwapDevice(nil), err
+	}
+
+	devices := processSwaps(buff)
+	for i, d := range devices {
+		if d.Type != SwapZram {
+			continue
+		}
+
+		z := readZramDevice(sysBlockPath, filepath.Base(d.Filename))
+		devices[i].Zram = &z
+	}
+
+	return devices, nil
+}
+
+// Returns every zram device, used as swap or not
+func ZramDevices() ([]ZramDevice, error) {
+	var devices []ZramDevice
+
+	names, err := filepath.Glob(filepath.Join(sysBlockPath, "zram*"))
+	if err != nil {
+		return devices, err
+	}
+
+	for _, name := range names {
+		devices = append(devices, readZramDevice(sysBlockPath, filepath.Base(name)))
+	}
+
+	return devices, nil
+}
+
+func Zswap() (ZswapInfo, error) {
+	zi := readZswapParameters(zswapParametersPath)
+	if !zi.Available {
+		return zi, nil
+	}
+
+	buff, err := getMemInfos()
+	if err != nil {
+		return zi, err
+	}
+
+	zi.CompressedSize, zi.OriginalSize = processZswapMemInfos(buff)
+
+	return zi, nil
+}
+
+// ----
+
+// /proc/swaps escapes white spaces in file names like /proc/mounts does
+func unescapeOctal(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+
+	var out []byte
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			c, err := strconv.ParseUint(s[i+1:i+4], 8, 8)
+			if err == nil {
+				out = append(out, byte(c))
+				i += 3
+				continue
+			}
+		}
+
+		out = append(out, s[i])
+	}
+
+	return string(out)
+}
+
+func processSwaps(buff string) []SwapDevice {
+	var devices []SwapDevice
+
+	for i, line := range strings.Split(buff, "\n") {
+		// first line holds the column names
+		if i == 0 {
+			continue
+		}
+
+		fields := strings.Fields(line)
+
+		// the path of a swap file removed while in use is followed by an
+		// unescaped " (deleted)"
+		if len(fields) == 6 && fields[1] == "(deleted)" {
+			fields = append(fields[:1], fields[2:]...)
+		}
+
+		if len(fields) != 5 {
+			continue
+		}
+
+		d := SwapDevice{
+			Filename: unescapeOctal(fields[0]),
+			Type:     fields[1],
+			Size:     uint64(atoi(fields[2])) * 1024,
+			Used:     uint64(atoi(fields[3])) * 1024,
+			Priority: atoi(fields[4]),
+		}
+
+		if strings.HasPrefix(d.Filename, "/dev/zram") {
+			d.Type = SwapZram
+		}
+
+		devices = append(devices, d)
+	}
+
+	return devices
+}
+
+func readZramDevice(root string, name string) ZramDevice {
+	dir := filepath.Join(root, name)
+
+	z := ZramDevice{
+		Name:          name,
+		DiskSize:      sysfsUint64(filepath.Join(dir, "disksize")),
+		CompAlgorithm: bracketedValue(sysfsString(filepath.Join(dir, "comp_algorithm"))),
+	}
+
+	// kernels >= 4.1 group the statistics in mm_stat
+	mmStat := strings.Fields(sysfsString(filepath.Join(dir, "mm_stat")))
+	if len(mmStat) >= 5 {
+		v := make([]uint64, 5)
+		for i := range v {
+			v[i], _ = strconv.ParseUint(mmStat[i], 10, 64)
+		}
+
+		z.OrigDataSize = v[0]
+		z.ComprDataSize = v[1]
+		z.MemUsedTotal = v[2]
+		z.MemLimit = v[3]
+		z.MemUsedMax = v[4]
+
+		return z
+	}
+
+	z.OrigDataSize = sysfsUint64(filepath.Join(dir, "orig_data_size"))
+	z.ComprDataSize = sysfsUint64(filepath.Join(dir, "compr_data_size"))
+	z.MemUsedTotal = sysfsUint64(filepath.Join(dir, "mem_used_total"))
+	z.MemLimit = sysfsUint64(filepath.Join(dir, "mem_limit"))
+	z.MemUsedMax = sysfsUint64(filepath.Join(dir, "mem_used_max"))
+
+	return z
+}
+
+func readZswapParameters(dir string) ZswapInfo {
+	enabled := sysfsString(filepath.Join(dir, "enabled"))
+	if enabled == "" {
+		return ZswapInfo{}
+	}
+
+	return ZswapInfo{
+		Available:      true,
+		Enabled:        enabled == "Y" || enabled == "1",
+		Compressor:     sysfsString(filepath.Join(dir, "compressor")),
+		Zpool:          sysfsString(filepath.Join(dir, "zpool")),
+		MaxPoolPercent: sysfsInt(filepath.Join(dir, "max_pool_percent")),
+	}
+}
+
+// Returns the compressed and original sizes of the pages stored in zswap
+func processZswapMemInfos(buff string) (uint64, uint64) {
+	var compressed, original uint64
+
+	for _, line := range strings.Split(buff, "\n") {
+		parts := strings.Fields(line)
+		if len(parts) != 3 {
+			continue
+		}
+
+		v, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		switch strings.TrimSuffix(parts[0], ":") {
+		case "Zswap":
+			compressed = v * 1024
+		case "Zswapped":
+			original = v * 1024
+		}
+	}
+
+	return compressed, original
+}
+
+// ----
+
+func getSwaps() (string, error) {
+	return readFile("/proc/swaps")
+}
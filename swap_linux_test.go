@@ -0,0 +1,119 @@
+package libsysinfo
+
+import (
+	. "launchpad.net/gocheck"
+)
+
+type SwapTestSuite struct{}
+
+var (
+	_ = Suite(&SwapTestSuite{})
+)
+
+func (s *SwapTestSuite) TestProcessSwaps(c *C) {
+	fixture := `Filename				Type		Size		Used		Priority
+/dev/sda5                               partition	466940	1024	-2
+/var/swap\040file                       file		102396	0	-3
+/dev/zram0                              partition	4194300	524288	100
+/tmp/old\040swap (deleted)              file		65532	4096	-4
+`
+	obtained := processSwaps(fixture)
+
+	expected := []SwapDevice{
+		SwapDevice{
+			Filename: "/dev/sda5",
+			Type:     SwapPartition,
+			Size:     478146560,
+			Used:     1048576,
+			Priority: -2,
+		},
+		SwapDevice{
+			Filename: "/var/swap file",
+			Type:     SwapFile,
+			Size:     104853504,
+			Priority: -3,
+		},
+		SwapDevice{
+			Filename: "/dev/zram0",
+			Type:     SwapZram,
+			Size:     4294963200,
+			Used:     536870912,
+			Priority: 100,
+		},
+		SwapDevice{
+			Filename: "/tmp/old swap",
+			Type:     SwapFile,
+			Size:     67104768,
+			Used:     4194304,
+			Priority: -4,
+		},
+	}
+
+	c.Assert(obtained, DeepEquals, expected)
+}
+
+func (s *SwapTestSuite) TestReadZramDevice(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"zram0/disksize":        "4294967296\n",
+		"zram0/comp_algorithm":  "lzo lzo-rle lz4 [zstd]\n",
+		"zram0/mm_stat":         "536870912 134217728 140509184        0 150994944     1203        0     4096     4096\n",
+		"zram1/disksize":        "1073741824\n",
+		"zram1/comp_algorithm":  "[lzo] lz4\n",
+		"zram1/orig_data_size":  "4096\n",
+		"zram1/compr_data_size": "1024\n",
+	})
+
+	obtained := readZramDevice(root, "zram0")
+
+	c.Assert(obtained, DeepEquals, ZramDevice{
+		Name:          "zram0",
+		DiskSize:      4294967296,
+		CompAlgorithm: "zstd",
+		OrigDataSize:  536870912,
+		ComprDataSize: 134217728,
+		MemUsedTotal:  140509184,
+		MemUsedMax:    150994944,
+	})
+	c.Assert(obtained.CompressionRatio(), Equals, 4.0)
+
+	obtained = readZramDevice(root, "zram1")
+
+	c.Assert(obtained.CompAlgorithm, Equals, "lzo")
+	c.Assert(obtained.OrigDataSize, Equals, uint64(4096))
+	c.Assert(obtained.ComprDataSize, Equals, uint64(1024))
+}
+
+func (s *SwapTestSuite) TestReadZswapParameters(c *C) {
+	root := c.MkDir()
+
+	c.Assert(readZswapParameters(root), DeepEquals, ZswapInfo{})
+
+	writeFixtureFiles(c, root, map[string]string{
+		"enabled":          "Y\n",
+		"compressor":       "zstd\n",
+		"zpool":            "zsmalloc\n",
+		"max_pool_percent": "20\n",
+	})
+
+	c.Assert(readZswapParameters(root), DeepEquals, ZswapInfo{
+		Available:      true,
+		Enabled:        true,
+		Compressor:     "zstd",
+		Zpool:          "zsmalloc",
+		MaxPoolPercent: 20,
+	})
+}
+
+func (s *SwapTestSuite) TestProcessZswapMemInfos(c *C) {
+	fixture := `SwapFree:         466940 kB
+Zswap:              2048 kB
+Zswapped:           8192 kB
+Dirty:                 0 kB
+`
+	compressed, original := processZswapMemInfos(fixture)
+
+	c.Assert(compressed, Equals, uint64(2097152))
+	c.Assert(original, Equals, uint64(8388608))
+}
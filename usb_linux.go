@@ -0,0 +1,339 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// USB class codes, see https://www.usb.org/defined-class-codes
+const (
+	USBClassPerInterface = 0x00
+	USBClassAudio        = 0x01
+	USBClassComm         = 0x02
+	USBClassHID          = 0x03
+	USBClassImage        = 0x06
+	USBClassPrinter      = 0x07
+	USBClassMassStorage  = 0x08
+	USBClassHub          = 0x09
+	USBClassVideo        = 0x0e
+	USBClassWireless     = 0xe0
+	USBClassMisc         = 0xef
+	USBClassVendorSpec   = 0xff
+)
+
+const (
+	sysUSBDevicesPath = "/sys/bus/usb/devices"
+)
+
+var (
+	// Locations searched for usb.ids, in order. The embedded subset is used
+	// when none exists.
+	USBIDsPaths = []string{
+		"/usr/share/hwdata/usb.ids",
+		"/usr/share/misc/usb.ids",
+		"/usr/share/usb.ids",
+		"/var/lib/usbutils/usb.ids",
+	}
+
+	usbIDsCache = newIDDatabases()
+)
+
+// ----
+
+type USBDevice struct {
+	// Kernel name, e.g. usb1 for a root hub or 1-1.2
+	Name string
+
+	Bus    int
+	Device int
+
+	// Ports from the root hub, e.g. 1.2. "0" for root hubs.
+	Path string
+
+	VendorID  uint16
+	ProductID uint16
+
+	// Strings reported by the device, often empty
+	Manufacturer string
+	Product      string
+	Serial       string
+
+	// Resolved from usb.ids, empty when unknown
+	VendorName  string
+	ProductName string
+
+	// In Mbit/s: 1.5, 12, 480, 5000, 10000 or 20000
+	Speed float64
+
+	// USB version supported by the device, e.g. 2.00
+	Version string
+
+	Class     uint8
+	Subclass  uint8
+	Protocol  uint8
+	ClassName string
+
+	// Active configuration, 0 when unconfigured
+	Configuration     int
+	NumConfigurations int
+
+	// In mA
+	MaxPower int
+
+	// removable, fixed or unknown
+	Removable string
+
+	// Interfaces of the active configuration
+	Interfaces []USBInterface
+
+	// Devices plugged into a hub
+	Children []USBDevice
+}
+
+type USBInterface struct {
+	// e.g. 1-1.2:1.0
+	Name string
+
+	Number           uint8
+	AlternateSetting uint8
+
+	Class     uint8
+	Subclass  uint8
+	Protocol  uint8
+	ClassName string
+
+	// Interface string reported by the device, or the name from usb.ids
+	Description string
+
+	// Empty when no driver is bound
+	Driver string
+}
+
+// ----
+
+// Returns the root hubs, with the devices plugged into them as children
+func USBDevices() ([]USBDevice, error) {
+	_, err := os.Stat(sysUSBDevicesPath)
+	if err != nil {
+		return []USBDevice(nil), err
+	}
+
+	db, err := USBIDs()
+	if err != nil {
+		return []USBDevice(nil), err
+	}
+
+	return readUSBDevices(sysUSBDevicesPath, db), nil
+}
+
+// Loads the first usb.ids found in USBIDsPaths, or the embedded subset. Each
+// file is parsed once and shared by the following lookups
+func USBIDs() (*IDDatabase, error) {
+	return usbIDsCache.load(USBIDsPaths, usbIDsFallback)
+}
+
+// Reports whether the device or one of its interfaces has the given class
+func (d USBDevice) HasClass(class uint8) bool {
+	if d.Class == class {
+		return true
+	}
+
+	for _, iface := range d.Interfaces {
+		if iface.Class == class {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reports whether the device exposes a mass storage interface, e.g. a USB
+// stick, a card reader or an external disk
+func (d USBDevice) IsMassStorage() bool {
+	return d.HasClass(USBClassMassStorage)
+}
+
+// Calls fn for the device and each of its descendants, depth first
+func (d USBDevice) Walk(fn func(USBDevice)) {
+	fn(d)
+
+	for _, child := range d.Children {
+		child.Walk(fn)
+	}
+}
+
+// ----
+
+func readUSBDevices(root string, db *IDDatabase) []USBDevice {
+	var roots []string
+
+	children := make(map[string][]string)
+	interfaces := make(map[string][]string)
+
+	for _, name := range dirNames(root) {
+		// interfaces are named <device>:<config>.<interface>, the ones of
+		// root hubs use port 0, e.g. 1-0:1.0
+		i := strings.Index(name, ":")
+		if i >= 0 {
+			parent := name[:i]
+			if strings.HasSuffix(parent, "-0") {
+				parent = "usb" + strings.TrimSuffix(parent, "-0")
+			}
+
+			interfaces[parent] = append(interfaces[parent], name)
+			continue
+		}
+
+		parent := usbParent(name)
+		if parent == "" {
+			roots = append(roots, name)
+			continue
+		}
+
+		children[parent] = append(children[parent], name)
+	}
+
+	var build func(name string) USBDevice
+	build = func(name string) USBDevice {
+		d := readUSBDevice(root, name, interfaces[name], db)
+
+		names := children[name]
+		sort.Sort(usbNamesByPort(names))
+
+		for _, child := range names {
+			d.Children = append(d.Children, build(child))
+		}
+
+		return d
+	}
+
+	var devices []USBDevice
+
+	sort.Sort(usbNamesByPort(roots))
+	for _, name := range roots {
+		devices = append(devices, build(name))
+	}
+
+	return devices
+}
+
+// Returns the name of the hub a device is plugged into, empty for root hubs
+func usbParent(name string) string {
+	if strings.HasPrefix(name, "usb") {
+		return ""
+	}
+
+	i := strings.LastIndex(name, ".")
+	if i >= 0 {
+		return name[:i]
+	}
+
+	i = strings.Index(name, "-")
+	if i < 0 {
+		return ""
+	}
+
+	return "usb" + name[:i]
+}
+
+func readUSBDevice(root string, name string, interfaces []string, db *IDDatabase) USBDevice {
+	dir := filepath.Join(root, name)
+
+	d := USBDevice{
+		Name:              name,
+		Bus:               sysfsInt(filepath.Join(dir, "busnum")),
+		Device:            sysfsInt(filepath.Join(dir, "devnum")),
+		Path:              sysfsString(filepath.Join(dir, "devpath")),
+		VendorID:          uint16(sysfsHex(filepath.Join(dir, "idVendor"))),
+		ProductID:         uint16(sysfsHex(filepath.Join(dir, "idProduct"))),
+		Manufacturer:      sysfsString(filepath.Join(dir, "manufacturer")),
+		Product:           sysfsString(filepath.Join(dir, "product")),
+		Serial:            sysfsString(filepath.Join(dir, "serial")),
+		Version:           sysfsString(filepath.Join(dir, "version")),
+		Class:             uint8(sysfsHex(filepath.Join(dir, "bDeviceClass"))),
+		Subclass:          uint8(sysfsHex(filepath.Join(dir, "bDeviceSubClass"))),
+		Protocol:          uint8(sysfsHex(filepath.Join(dir, "bDeviceProtocol"))),
+		Configuration:     sysfsInt(filepath.Join(dir, "bConfigurationValue")),
+		NumConfigurations: sysfsInt(filepath.Join(dir, "bNumConfigurations")),
+		MaxPower:          atoi0(strings.TrimSuffix(sysfsString(filepath.Join(dir, "bMaxPower")), "mA")),
+		Removable:         sysfsString(filepath.Join(dir, "removable")),
+	}
+
+	d.Speed, _ = strconv.ParseFloat(sysfsString(filepath.Join(dir, "speed")), 64)
+
+	if db != nil {
+		d.VendorName = db.VendorName(d.VendorID)
+		d.ProductName = db.DeviceName(d.VendorID, d.ProductID)
+		d.ClassName = db.ClassName(d.Class)
+	}
+
+	sort.Sort(usbNamesByPort(interfaces))
+	for _, iface := range interfaces {
+		d.Interfaces = append(d.Interfaces, readUSBInterface(root, iface, d, db))
+	}
+
+	return d
+}
+
+func readUSBInterface(root string, name string, d USBDevice, db *IDDatabase) USBInterface {
+	dir := filepath.Join(root, name)
+
+	iface := USBInterface{
+		Name:             name,
+		Number:           uint8(sysfsHex(filepath.Join(dir, "bInterfaceNumber"))),
+		AlternateSetting: uint8(sysfsInt(filepath.Join(dir, "bAlternateSetting"))),
+		Class:            uint8(sysfsHex(filepath.Join(dir, "bInterfaceClass"))),
+		Subclass:         uint8(sysfsHex(filepath.Join(dir, "bInterfaceSubClass"))),
+		Protocol:         uint8(sysfsHex(filepath.Join(dir, "bInterfaceProtocol"))),
+		Description:      sysfsString(filepath.Join(dir, "interface")),
+		Driver:           linkBase(filepath.Join(dir, "driver")),
+	}
+
+	if db != nil {
+		iface.ClassName = db.ClassName(iface.Class)
+
+		if iface.Description == "" {
+			iface.Description = db.InterfaceName(d.VendorID, d.ProductID, iface.Number)
+		}
+	}
+
+	return iface
+}
+
+// ----
+
+// Sorts usbN, bus-port.port... and bus-port...:config.interface names
+// numerically
+type usbNamesByPort []string
+
+func (n usbNamesByPort) Len() int {
+	return len(n)
+}
+
+func (n usbNamesByPort) Swap(i, j int) {
+	n[i], n[j] = n[j], n[i]
+}
+
+func (n usbNamesByPort) Less(i, j int) bool {
+	split := func(name string) []string {
+		return strings.FieldsFunc(strings.TrimPrefix(name, "usb"), func(r rune) bool {
+			return r == '-' || r == '.' || r == ':'
+		})
+	}
+
+	a, b := split(n[i]), split(n[j])
+
+	for k := 0; k < len(a) && k < len(b); k++ {
+		x, y := atoi0(a[k]), atoi0(b[k])
+		if x != y {
+			return x < y
+		}
+	}
+
+	return len(a) < len(b)
+}
@@ -0,0 +1,230 @@
+package libsysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	. "launchpad.net/gocheck"
+)
+
+type USBTestSuite struct{}
+
+var (
+	_ = Suite(&USBTestSuite{})
+)
+
+func (s *USBTestSuite) TestReadUSBDevices(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"devices/usb1/busnum":                   "1\n",
+		"devices/usb1/devnum":                   "1\n",
+		"devices/usb1/devpath":                  "0\n",
+		"devices/usb1/idVendor":                 "1d6b\n",
+		"devices/usb1/idProduct":                "0002\n",
+		"devices/usb1/manufacturer":             "Linux 6.1.0 xhci-hcd\n",
+		"devices/usb1/product":                  "xHCI Host Controller\n",
+		"devices/usb1/serial":                   "0000:00:14.0\n",
+		"devices/usb1/speed":                    "480\n",
+		"devices/usb1/version":                  " 2.00\n",
+		"devices/usb1/bDeviceClass":             "09\n",
+		"devices/usb1/bConfigurationValue":      "1\n",
+		"devices/usb1/bNumConfigurations":       "1\n",
+		"devices/usb1/bMaxPower":                "0mA\n",
+		"devices/usb1/removable":                "unknown\n",
+		"devices/1-0:1.0/bInterfaceNumber":      "00\n",
+		"devices/1-0:1.0/bInterfaceClass":       "09\n",
+		"devices/1-1/busnum":                    "1\n",
+		"devices/1-1/devnum":                    "2\n",
+		"devices/1-1/devpath":                   "1\n",
+		"devices/1-1/idVendor":                  "05e3\n",
+		"devices/1-1/idProduct":                 "0610\n",
+		"devices/1-1/speed":                     "480\n",
+		"devices/1-1/bDeviceClass":              "09\n",
+		"devices/1-1/bMaxPower":                 "100mA\n",
+		"devices/1-1/removable":                 "fixed\n",
+		"devices/1-1:1.0/bInterfaceClass":       "09\n",
+		"devices/1-1.10/busnum":                 "1\n",
+		"devices/1-1.10/devpath":                "1.10\n",
+		"devices/1-1.10/idVendor":               "0627\n",
+		"devices/1-1.10/idProduct":              "0001\n",
+		"devices/1-1.10/speed":                  "12\n",
+		"devices/1-1.10/bDeviceClass":           "00\n",
+		"devices/1-1.10:1.0/bAlternateSetting":  "10\n",
+		"devices/1-1.10:1.0/bInterfaceClass":    "03\n",
+		"devices/1-1.10:1.0/bInterfaceSubClass": "01\n",
+		"devices/1-1.10:1.0/bInterfaceProtocol": "02\n",
+		"devices/1-1.2/busnum":                  "1\n",
+		"devices/1-1.2/devnum":                  "3\n",
+		"devices/1-1.2/devpath":                 "1.2\n",
+		"devices/1-1.2/idVendor":                "0781\n",
+		"devices/1-1.2/idProduct":               "5581\n",
+		"devices/1-1.2/manufacturer":            " SanDisk'\n",
+		"devices/1-1.2/product":                 " SanDisk 3.2Gen1\n",
+		"devices/1-1.2/serial":                  "4C530001230812110342\n",
+		"devices/1-1.2/speed":                   "5000\n",
+		"devices/1-1.2/version":                 " 3.20\n",
+		"devices/1-1.2/bDeviceClass":            "00\n",
+		"devices/1-1.2/bConfigurationValue":     "1\n",
+		"devices/1-1.2/bNumConfigurations":      "1\n",
+		"devices/1-1.2/bMaxPower":               "896mA\n",
+		"devices/1-1.2/removable":               "removable\n",
+		"devices/1-1.2:1.0/bInterfaceNumber":    "00\n",
+		"devices/1-1.2:1.0/bAlternateSetting":   " 0\n",
+		"devices/1-1.2:1.0/bInterfaceClass":     "08\n",
+		"devices/1-1.2:1.0/bInterfaceSubClass":  "06\n",
+		"devices/1-1.2:1.0/bInterfaceProtocol":  "50\n",
+		"devices/usb2/busnum":                   "2\n",
+		"devices/usb2/devpath":                  "0\n",
+		"devices/usb2/idVendor":                 "1d6b\n",
+		"devices/usb2/idProduct":                "0003\n",
+		"devices/usb2/speed":                    "5000\n",
+		"devices/usb2/bDeviceClass":             "09\n",
+		"drivers/usb-storage/.keep":             "",
+		"drivers/usbhid/.keep":                  "",
+	})
+
+	devices := filepath.Join(root, "devices")
+
+	c.Assert(os.Symlink("../../drivers/usb-storage", filepath.Join(devices, "1-1.2:1.0/driver")), IsNil)
+	c.Assert(os.Symlink("../../drivers/usbhid", filepath.Join(devices, "1-1.10:1.0/driver")), IsNil)
+
+	db, err := ParseIDDatabase(strings.NewReader(usbIDsFallback))
+	c.Assert(err, IsNil)
+
+	roots := readUSBDevices(devices, db)
+	c.Assert(roots, HasLen, 2)
+
+	hub := roots[0]
+	c.Check(hub.Name, Equals, "usb1")
+	c.Check(hub.Bus, Equals, 1)
+	c.Check(hub.Device, Equals, 1)
+	c.Check(hub.Path, Equals, "0")
+	c.Check(hub.VendorName, Equals, "Linux Foundation")
+	c.Check(hub.ProductName, Equals, "2.0 root hub")
+	c.Check(hub.Speed, Equals, 480.0)
+	c.Check(hub.Version, Equals, "2.00")
+	c.Check(hub.ClassName, Equals, "Hub")
+	c.Check(hub.Removable, Equals, "unknown")
+	c.Check(hub.Interfaces, HasLen, 1)
+	c.Check(hub.Interfaces[0].Name, Equals, "1-0:1.0")
+	c.Check(hub.IsMassStorage(), Equals, false)
+	c.Assert(hub.Children, HasLen, 1)
+
+	c.Check(roots[1].Name, Equals, "usb2")
+	c.Check(roots[1].ProductName, Equals, "3.0 root hub")
+	c.Check(roots[1].Children, HasLen, 0)
+
+	external := hub.Children[0]
+	c.Check(external.Name, Equals, "1-1")
+	c.Check(external.VendorName, Equals, "Genesys Logic, Inc.")
+	c.Check(external.MaxPower, Equals, 100)
+	c.Check(external.HasClass(USBClassHub), Equals, true)
+
+	// ports sort numerically
+	c.Assert(external.Children, HasLen, 2)
+	c.Check(external.Children[0].Name, Equals, "1-1.2")
+	c.Check(external.Children[1].Name, Equals, "1-1.10")
+
+	stick := external.Children[0]
+	c.Check(stick.Device, Equals, 3)
+	c.Check(stick.Path, Equals, "1.2")
+	c.Check(stick.VendorID, Equals, uint16(0x0781))
+	c.Check(stick.ProductID, Equals, uint16(0x5581))
+	c.Check(stick.Manufacturer, Equals, "SanDisk'")
+	c.Check(stick.Product, Equals, "SanDisk 3.2Gen1")
+	c.Check(stick.Serial, Equals, "4C530001230812110342")
+	c.Check(stick.VendorName, Equals, "SanDisk Corp.")
+	c.Check(stick.ProductName, Equals, "")
+	c.Check(stick.Speed, Equals, 5000.0)
+	c.Check(stick.Class, Equals, uint8(USBClassPerInterface))
+	c.Check(stick.Configuration, Equals, 1)
+	c.Check(stick.NumConfigurations, Equals, 1)
+	c.Check(stick.MaxPower, Equals, 896)
+	c.Check(stick.Removable, Equals, "removable")
+	c.Check(stick.IsMassStorage(), Equals, true)
+	c.Check(stick.Interfaces, DeepEquals, []USBInterface{
+		{
+			Name:      "1-1.2:1.0",
+			Class:     USBClassMassStorage,
+			Subclass:  0x06,
+			Protocol:  0x50,
+			ClassName: "Mass Storage",
+			Driver:    "usb-storage",
+		},
+	})
+
+	tablet := external.Children[1]
+	c.Check(tablet.Speed, Equals, 12.0)
+	c.Check(tablet.ProductName, Equals, "QEMU Tablet")
+	c.Check(tablet.IsMassStorage(), Equals, false)
+	c.Check(tablet.Interfaces[0].ClassName, Equals, "Human Interface Device")
+	c.Check(tablet.Interfaces[0].Driver, Equals, "usbhid")
+	c.Check(tablet.Interfaces[0].AlternateSetting, Equals, uint8(10))
+
+	var walked []string
+	hub.Walk(func(d USBDevice) {
+		walked = append(walked, d.Name)
+	})
+	c.Check(walked, DeepEquals, []string{"usb1", "1-1", "1-1.2", "1-1.10"})
+}
+
+func (s *USBTestSuite) TestUSBIDs_Fallback(c *C) {
+	saved := USBIDsPaths
+	defer func() { USBIDsPaths = saved }()
+
+	USBIDsPaths = []string{filepath.Join(c.MkDir(), "missing")}
+
+	db, err := USBIDs()
+	c.Assert(err, IsNil)
+	c.Assert(db.DeviceName(0x1d6b, 0x0002), Equals, "2.0 root hub")
+}
+
+func (s *USBTestSuite) TestUSBIDs_Paths(c *C) {
+	saved := USBIDsPaths
+	defer func() { USBIDsPaths = saved }()
+
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"a/usb.ids": "1d6b  Linux Foundation\n",
+		"b/usb.ids": "1d6b  The Linux Foundation\n",
+	})
+
+	USBIDsPaths = []string{filepath.Join(root, "a/usb.ids")}
+
+	db, err := USBIDs()
+	c.Assert(err, IsNil)
+	c.Assert(db.VendorName(0x1d6b), Equals, "Linux Foundation")
+
+	again, err := USBIDs()
+	c.Assert(err, IsNil)
+	c.Assert(again == db, Equals, true)
+
+	USBIDsPaths = []string{filepath.Join(root, "b/usb.ids")}
+
+	db, err = USBIDs()
+	c.Assert(err, IsNil)
+	c.Assert(db.VendorName(0x1d6b), Equals, "The Linux Foundation")
+}
+
+func (s *USBTestSuite) TestUSBParent(c *C) {
+	c.Check(usbParent("usb3"), Equals, "")
+	c.Check(usbParent("3-2"), Equals, "usb3")
+	c.Check(usbParent("3-2.4"), Equals, "3-2")
+	c.Check(usbParent("3-2.4.1"), Equals, "3-2.4")
+}
+
+func (s *USBTestSuite) TestUSBNamesByPort(c *C) {
+	names := []string{"usb10", "1-1.10", "usb2", "1-1.2", "1-10", "1-2", "usb1"}
+	sort.Sort(usbNamesByPort(names))
+
+	c.Check(names, DeepEquals, []string{"usb1", "1-1.2", "1-1.10", "1-2", "1-10", "usb2", "usb10"})
+
+	interfaces := []string{"1-1:1.10", "1-1:2.0", "1-1:1.2", "1-1:1.0"}
+	sort.Sort(usbNamesByPort(interfaces))
+
+	c.Check(interfaces, DeepEquals, []string{"1-1:1.0", "1-1:1.2", "1-1:1.10", "1-1:2.0"})
+}
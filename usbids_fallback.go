@@ -0,0 +1,88 @@
+package libsysinfo
+
+// Subset of usb.ids used when no database is installed. It holds every
+// device class and a few common vendors.
+const usbIDsFallback = `
+0403  Future Technology Devices International, Ltd
+0409  NEC Corp.
+045e  Microsoft Corp.
+046d  Logitech, Inc.
+04e8  Samsung Electronics Co., Ltd
+04f2  Chicony Electronics Co., Ltd
+05ac  Apple, Inc.
+05e3  Genesys Logic, Inc.
+0627  Adomax Technology Co., Ltd
+	0001  QEMU Tablet
+067b  Prolific Technology, Inc.
+0781  SanDisk Corp.
+0951  Kingston Technology
+0bda  Realtek Semiconductor Corp.
+1050  Yubico.com
+18d1  Google Inc.
+1a86  QinHeng Electronics
+1d6b  Linux Foundation
+	0001  1.1 root hub
+	0002  2.0 root hub
+	0003  3.0 root hub
+2109  VIA Labs, Inc.
+413c  Dell Computer Corp.
+8087  Intel Corp.
+C 00  (Defined at Interface level)
+C 01  Audio
+	01  Control Device
+	02  Streaming
+	03  MIDI Streaming
+C 02  Communications
+	02  Abstract (modem)
+	06  Ethernet Networking
+	0d  Network Control Model
+C 03  Human Interface Device
+	00  No Subclass
+	01  Boot Interface Subclass
+		01  Keyboard
+		02  Mouse
+C 05  Physical Interface Device
+C 06  Imaging
+	01  Still Image Capture
+		01  Picture Transfer Protocol (PIMA 15470)
+C 07  Printer
+	01  Printer
+C 08  Mass Storage
+	01  RBC (typically Flash)
+	02  SFF-8020i, MMC-2 (ATAPI)
+	03  QIC-157
+	04  Floppy (UFI)
+	05  SFF-8070i
+	06  SCSI
+		00  Control/Bulk/Interrupt
+		01  Control/Bulk
+		50  Bulk-Only
+		62  UAS
+C 09  Hub
+	00  Unused
+		00  Full speed (or root) hub
+		01  Single TT
+		02  TT per port
+		03  USB 3.0 hub
+C 0a  CDC Data
+C 0b  Chip/SmartCard
+C 0d  Content Security
+C 0e  Video
+	01  Video Control
+	02  Video Streaming
+C 0f  Personal Healthcare
+C 10  Audio/Video
+C 11  Billboard
+C 12  Type-C Bridge
+C dc  Diagnostic
+C e0  Wireless
+	01  Radio Frequency
+		01  Bluetooth
+C ef  Miscellaneous Device
+	02  Common Class
+		01  Interface Association
+C fe  Application Specific Interface
+	01  Device Firmware Update
+	03  Test and Measurement
+C ff  Vendor Specific Class
+`
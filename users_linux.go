@@ -0,0 +1,384 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// utmp record types, see utmp(5)
+const (
+	SessionEmpty SessionType = iota
+	SessionRunLevel
+	SessionBootTime
+	SessionNewTime
+	SessionOldTime
+	SessionInitProcess
+	SessionLoginProcess
+	SessionUserProcess
+	SessionDeadProcess
+	SessionAccounting
+)
+
+// Layout of struct utmp as written by glibc on Linux. Timestamps are 32 bits
+// wide even on 64 bits systems.
+const (
+	utmpRecordLen = 384
+
+	utmpTypeOffset    = 0
+	utmpPIDOffset     = 4
+	utmpLineOffset    = 8
+	utmpIDOffset      = 40
+	utmpUserOffset    = 44
+	utmpHostOffset    = 76
+	utmpExitOffset    = 332
+	utmpSessionOffset = 336
+	utmpTimeOffset    = 340
+	utmpAddrOffset    = 348
+
+	utmpLineLen = 32
+	utmpIDLen   = 4
+	utmpUserLen = 32
+	utmpHostLen = 256
+	utmpAddrLen = 16
+)
+
+const (
+	utmpPath = "/var/run/utmp"
+	wtmpPath = "/var/log/wtmp"
+)
+
+const (
+	secondsPerDay = 24 * 60 * 60
+)
+
+// ----
+
+type User struct {
+	Name  string
+	UID   int
+	GID   int
+	Gecos string
+	Home  string
+	Shell string
+
+	// nil when /etc/shadow is not readable or has no entry for the user
+	Shadow *ShadowInfo
+}
+
+// Password aging informations from /etc/shadow. Password hashes are
+// deliberately left out.
+type ShadowInfo struct {
+	// Set when the password field starts with "!" or "*", which no hash
+	// can match
+	Locked bool
+
+	// Set when the password field is empty, the account can then log in
+	// without a password
+	NoPassword bool
+
+	// Zero when aging is disabled or when MustChangePassword is set
+	LastChange time.Time
+
+	// Set when the date of the last change is 0, the user must then change
+	// the password at next login
+	MustChangePassword bool
+
+	// Number of days, -1 when not set
+	MinAge       int
+	MaxAge       int
+	WarnPeriod   int
+	InactiveDays int
+
+	// Zero when the account never expires
+	Expires time.Time
+}
+
+type Group struct {
+	Name    string
+	GID     int
+	Members []string
+}
+
+type SessionType int
+
+func (t SessionType) String() string {
+	switch t {
+	case SessionEmpty:
+		return "empty"
+	case SessionRunLevel:
+		return "run_lvl"
+	case SessionBootTime:
+		return "boot_time"
+	case SessionNewTime:
+		return "new_time"
+	case SessionOldTime:
+		return "old_time"
+	case SessionInitProcess:
+		return "init_process"
+	case SessionLoginProcess:
+		return "login_process"
+	case SessionUserProcess:
+		return "user_process"
+	case SessionDeadProcess:
+		return "dead_process"
+	case SessionAccounting:
+		return "accounting"
+	}
+
+	return "unknown"
+}
+
+type Session struct {
+	Type      SessionType
+	PID       int
+	TTY       string
+	ID        string
+	User      string
+	Host      string
+	Addr      net.IP
+	LoginTime time.Time
+	SessionID int
+
+	// Only meaningful for SessionDeadProcess records
+	Termination int
+	ExitStatus  int
+}
+
+// ----
+
+// Returns the local users from /etc/passwd. Password aging informations are
+// added when /etc/shadow is readable, which usually requires root.
+func Users() ([]User, error) {
+	buff, err := getPasswd()
+	if err != nil {
+		return []User(nil), err
+	}
+
+	users := processPasswd(buff)
+
+	buff, err = getShadow()
+	if os.IsNotExist(err) || os.IsPermission(err) {
+		return users, nil
+	}
+	if err != nil {
+		return users, err
+	}
+
+	shadow := processShadow(buff)
+	for i := range users {
+		si, exists := shadow[users[i].Name]
+		if exists {
+			users[i].Shadow = &si
+		}
+	}
+
+	return users, nil
+}
+
+func Groups() ([]Group, error) {
+	buff, err := getGroups()
+	if err != nil {
+		return []Group(nil), err
+	}
+
+	return processGroups(buff), nil
+}
+
+// Returns the records of /var/run/utmp, i.e. who is currently logged in
+func Sessions() ([]Session, error) {
+	return readUtmp(utmpPath)
+}
+
+// Returns the records of /var/log/wtmp, i.e. every login, logout and reboot
+// since the file was last rotated
+func LoginHistory() ([]Session, error) {
+	return readUtmp(wtmpPath)
+}
+
+// ----
+
+func readUtmp(path string) ([]Session, error) {
+	buff, err := ioutil.ReadFile(path)
+	if err != nil {
+		return []Session(nil), err
+	}
+
+	return processUtmp(buff), nil
+}
+
+// Converts a number of days since the epoch, as used in /etc/shadow
+func daysToTime(days string) time.Time {
+	d, err := strconv.ParseInt(days, 10, 64)
+	if err != nil || d <= 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(d*secondsPerDay, 0).UTC()
+}
+
+func daysOrUnset(days string) int {
+	d, err := strconv.Atoi(days)
+	if err != nil {
+		return -1
+	}
+
+	return d
+}
+
+// Returns the content of a NUL padded C string
+func cString(b []byte) string {
+	pos := bytes.IndexByte(b, 0)
+	if pos > -1 {
+		b = b[:pos]
+	}
+
+	return string(b)
+}
+
+func processPasswd(buff string) []User {
+	var users []User
+
+	for _, line := range strings.Split(buff, "\n") {
+		parts := strings.Split(line, ":")
+		if len(parts) != 7 {
+			continue
+		}
+
+		uid, err := strconv.Atoi(parts[2])
+		if err != nil {
+			continue
+		}
+
+		gid, err := strconv.Atoi(parts[3])
+		if err != nil {
+			continue
+		}
+
+		users = append(users, User{
+			Name:  parts[0],
+			UID:   uid,
+			GID:   gid,
+			Gecos: parts[4],
+			Home:  parts[5],
+			Shell: parts[6],
+		})
+	}
+
+	return users
+}
+
+func processShadow(buff string) map[string]ShadowInfo {
+	shadow := make(map[string]ShadowInfo)
+
+	for _, line := range strings.Split(buff, "\n") {
+		parts := strings.Split(line, ":")
+		if len(parts) != 9 {
+			continue
+		}
+
+		hash := parts[1]
+
+		shadow[parts[0]] = ShadowInfo{
+			Locked:             strings.HasPrefix(hash, "!") || strings.HasPrefix(hash, "*"),
+			NoPassword:         hash == "",
+			LastChange:         daysToTime(parts[2]),
+			MustChangePassword: parts[2] == "0",
+			MinAge:             daysOrUnset(parts[3]),
+			MaxAge:             daysOrUnset(parts[4]),
+			WarnPeriod:         daysOrUnset(parts[5]),
+			InactiveDays:       daysOrUnset(parts[6]),
+			Expires:            daysToTime(parts[7]),
+		}
+	}
+
+	return shadow
+}
+
+func processGroups(buff string) []Group {
+	var groups []Group
+
+	for _, line := range strings.Split(buff, "\n") {
+		parts := strings.Split(line, ":")
+		if len(parts) != 4 {
+			continue
+		}
+
+		gid, err := strconv.Atoi(parts[2])
+		if err != nil {
+			continue
+		}
+
+		g := Group{
+			Name: parts[0],
+			GID:  gid,
+		}
+
+		if parts[3] != "" {
+			g.Members = strings.Split(parts[3], ",")
+		}
+
+		groups = append(groups, g)
+	}
+
+	return groups
+}
+
+func processUtmp(buff []byte) []Session {
+	var sessions []Session
+
+	for len(buff) >= utmpRecordLen {
+		r := buff[:utmpRecordLen]
+		buff = buff[utmpRecordLen:]
+
+		s := Session{
+			Type:        SessionType(int16(nativeEndian.Uint16(r[utmpTypeOffset:]))),
+			PID:         int(int32(nativeEndian.Uint32(r[utmpPIDOffset:]))),
+			TTY:         cString(r[utmpLineOffset : utmpLineOffset+utmpLineLen]),
+			ID:          cString(r[utmpIDOffset : utmpIDOffset+utmpIDLen]),
+			User:        cString(r[utmpUserOffset : utmpUserOffset+utmpUserLen]),
+			Host:        cString(r[utmpHostOffset : utmpHostOffset+utmpHostLen]),
+			Termination: int(int16(nativeEndian.Uint16(r[utmpExitOffset:]))),
+			ExitStatus:  int(int16(nativeEndian.Uint16(r[utmpExitOffset+2:]))),
+			SessionID:   int(int32(nativeEndian.Uint32(r[utmpSessionOffset:]))),
+		}
+
+		sec := int64(int32(nativeEndian.Uint32(r[utmpTimeOffset:])))
+		usec := int64(int32(nativeEndian.Uint32(r[utmpTimeOffset+4:])))
+		s.LoginTime = time.Unix(sec, usec*1000).UTC()
+
+		addr := r[utmpAddrOffset : utmpAddrOffset+utmpAddrLen]
+		switch {
+		case bytes.Count(addr, []byte{0}) == utmpAddrLen:
+			// no remote address
+		case bytes.Count(addr[net.IPv4len:], []byte{0}) == utmpAddrLen-net.IPv4len:
+			s.Addr = net.IP(append([]byte(nil), addr[:net.IPv4len]...))
+		default:
+			s.Addr = net.IP(append([]byte(nil), addr...))
+		}
+
+		sessions = append(sessions, s)
+	}
+
+	return sessions
+}
+
+// ----
+
+func getPasswd() (string, error) {
+	return readFile("/etc/passwd")
+}
+
+func getShadow() (string, error) {
+	return readFile("/etc/shadow")
+}
+
+func getGroups() (string, error) {
+	return readFile("/etc/group")
+}
@@ -0,0 +1,140 @@
+package libsysinfo
+
+import (
+	. "launchpad.net/gocheck"
+	"net"
+	"time"
+)
+
+type UsersTestSuite struct{}
+
+var (
+	_ = Suite(&UsersTestSuite{})
+)
+
+func (s *UsersTestSuite) TestProcessPasswd(c *C) {
+	fixture := `root:x:0:0:root:/root:/bin/bash
+daemon:x:1:1:daemon:/usr/sbin:/usr/sbin/nologin
+vagrant:x:1000:1000:vagrant,,,:/home/vagrant:/bin/bash
+broken:x:abc:1000::/:/bin/false
++@netgroup
+`
+	obtained := processPasswd(fixture)
+
+	expected := []User{
+		User{Name: "root", UID: 0, GID: 0, Gecos: "root", Home: "/root", Shell: "/bin/bash"},
+		User{Name: "daemon", UID: 1, GID: 1, Gecos: "daemon", Home: "/usr/sbin", Shell: "/usr/sbin/nologin"},
+		User{Name: "vagrant", UID: 1000, GID: 1000, Gecos: "vagrant,,,", Home: "/home/vagrant", Shell: "/bin/bash"},
+	}
+
+	c.Assert(obtained, DeepEquals, expected)
+}
+
+func (s *UsersTestSuite) TestProcessShadow(c *C) {
+	fixture := `root:$6$salt$hash:15887:0:99999:7:::
+daemon:*:15887:0:99999:7:::
+vagrant:!$6$salt$hash:15887:1:90:14:30:16000:
+guest::15887:0:99999:7:::
+intern:$6$salt$hash:0:0:99999:7:::
+nobody:*:::::::
+`
+	obtained := processShadow(fixture)
+
+	c.Assert(len(obtained), Equals, 6)
+
+	c.Assert(obtained["root"], DeepEquals, ShadowInfo{
+		LastChange:   time.Date(2013, time.July, 1, 0, 0, 0, 0, time.UTC),
+		MinAge:       0,
+		MaxAge:       99999,
+		WarnPeriod:   7,
+		InactiveDays: -1,
+	})
+
+	c.Assert(obtained["daemon"].Locked, Equals, true)
+	c.Assert(obtained["daemon"].NoPassword, Equals, false)
+
+	c.Assert(obtained["vagrant"].Locked, Equals, true)
+	c.Assert(obtained["vagrant"].InactiveDays, Equals, 30)
+	c.Assert(obtained["vagrant"].Expires, Equals, time.Date(2013, time.October, 22, 0, 0, 0, 0, time.UTC))
+
+	// an empty password field lets anyone log in, it is not a lock
+	c.Assert(obtained["guest"].Locked, Equals, false)
+	c.Assert(obtained["guest"].NoPassword, Equals, true)
+
+	// 0 forces a password change, an empty field disables aging
+	c.Assert(obtained["intern"].MustChangePassword, Equals, true)
+	c.Assert(obtained["intern"].LastChange.IsZero(), Equals, true)
+	c.Assert(obtained["nobody"].MustChangePassword, Equals, false)
+	c.Assert(obtained["nobody"].LastChange.IsZero(), Equals, true)
+	c.Assert(obtained["root"].MustChangePassword, Equals, false)
+}
+
+func (s *UsersTestSuite) TestProcessGroups(c *C) {
+	fixture := `root:x:0:
+sudo:x:27:vagrant,admin
+vagrant:x:1000:
+`
+	obtained := processGroups(fixture)
+
+	expected := []Group{
+		Group{Name: "root", GID: 0},
+		Group{Name: "sudo", GID: 27, Members: []string{"vagrant", "admin"}},
+		Group{Name: "vagrant", GID: 1000},
+	}
+
+	c.Assert(obtained, DeepEquals, expected)
+}
+
+func (s *UsersTestSuite) TestProcessUtmp(c *C) {
+	login := time.Date(2013, time.July, 1, 10, 30, 0, 0, time.UTC)
+
+	boot := utmpRecord(SessionBootTime, 0, "~", "~~", "reboot", "3.2.0-4-amd64", nil, login)
+	user := utmpRecord(SessionUserProcess, 2412, "pts/0", "ts/0", "vagrant", "10.0.2.2", net.IP{10, 0, 2, 2}, login)
+	user6 := utmpRecord(SessionUserProcess, 2413, "pts/1", "ts/1", "vagrant", "2001:db8::1", net.ParseIP("2001:db8::1"), login)
+
+	buff := append(append(boot, user...), user6...)
+	// trailing garbage of a partially written record is ignored
+	buff = append(buff, 0, 0, 0)
+
+	obtained := processUtmp(buff)
+
+	c.Assert(len(obtained), Equals, 3)
+
+	c.Assert(obtained[0].Type, Equals, SessionBootTime)
+	c.Assert(obtained[0].User, Equals, "reboot")
+	c.Assert(obtained[0].Addr, IsNil)
+
+	c.Assert(obtained[1], DeepEquals, Session{
+		Type:      SessionUserProcess,
+		PID:       2412,
+		TTY:       "pts/0",
+		ID:        "ts/0",
+		User:      "vagrant",
+		Host:      "10.0.2.2",
+		Addr:      net.IP{10, 0, 2, 2},
+		LoginTime: login,
+	})
+
+	c.Assert(obtained[2].Addr.String(), Equals, "2001:db8::1")
+	c.Assert(obtained[2].Type.String(), Equals, "user_process")
+}
+
+func utmpRecord(t SessionType, pid int, line, id, user, host string, addr net.IP, tv time.Time) []byte {
+	r := make([]byte, utmpRecordLen)
+
+	nativeEndian.PutUint16(r[utmpTypeOffset:], uint16(t))
+	nativeEndian.PutUint32(r[utmpPIDOffset:], uint32(pid))
+	copy(r[utmpLineOffset:utmpLineOffset+utmpLineLen], line)
+	copy(r[utmpIDOffset:utmpIDOffset+utmpIDLen], id)
+	copy(r[utmpUserOffset:utmpUserOffset+utmpUserLen], user)
+	copy(r[utmpHostOffset:utmpHostOffset+utmpHostLen], host)
+	nativeEndian.PutUint32(r[utmpTimeOffset:], uint32(tv.Unix()))
+
+	if v4 := addr.To4(); v4 != nil {
+		copy(r[utmpAddrOffset:], v4)
+	} else {
+		copy(r[utmpAddrOffset:], addr)
+	}
+
+	return r
+}
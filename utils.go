@@ -1,9 +1,31 @@
 package libsysinfo
 
 import (
+	"encoding/binary"
+	"encoding/hex"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+	"unsafe"
 )
 
+// The byte order used by the kernel when it prints raw addresses as
+// hexadecimal words in /proc/net/*
+var nativeEndian binary.ByteOrder
+
+func init() {
+	i := uint16(1)
+	if *(*byte)(unsafe.Pointer(&i)) == 1 {
+		nativeEndian = binary.LittleEndian
+	} else {
+		nativeEndian = binary.BigEndian
+	}
+}
+
 func atoi(a string) int {
 	i, err := strconv.Atoi(a)
 	if err != nil {
@@ -13,6 +35,16 @@ func atoi(a string) int {
 	return i
 }
 
+// Same as atoi, returning 0 on error
+func atoi0(a string) int {
+	i, err := strconv.Atoi(strings.TrimSpace(a))
+	if err != nil {
+		return 0
+	}
+
+	return i
+}
+
 func atof64(s string) float64 {
 	f, err := strconv.ParseFloat(s, 32)
 	if err != nil {
@@ -21,3 +53,172 @@ func atof64(s string) float64 {
 
 	return f
 }
+
+func hextoui64(h string) uint64 {
+	i, err := strconv.ParseUint(h, 16, 64)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return i
+}
+
+func containsString(strs []string, s string) bool {
+	for _, v := range strs {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func readFile(path string) (string, error) {
+	buff, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return string(buff), nil
+}
+
+// hextoipv4 decodes an IPv4 address printed by the kernel as a single
+// hexadecimal word in host byte order, e.g. "0102A8C0" for 192.168.2.1 on
+// little endian systems
+func hextoipv4(h string) net.IP {
+	ip := make(net.IP, net.IPv4len)
+	nativeEndian.PutUint32(ip, uint32(hextoui64(h)))
+
+	return ip
+}
+
+// hextoipv6 decodes an IPv6 address printed by the kernel as 32 hexadecimal
+// digits in network byte order
+func hextoipv6(h string) net.IP {
+	ip, err := hex.DecodeString(h)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	if len(ip) != net.IPv6len {
+		panic("invalid IPv6 address: " + h)
+	}
+
+	return net.IP(ip)
+}
+
+// sysfs attributes are optional and depend on the kernel version and the
+// driver, the following helpers return zero values for missing attributes
+
+func sysfsString(path string) string {
+	buff, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(buff))
+}
+
+func sysfsInt(path string) int {
+	i, err := strconv.Atoi(sysfsString(path))
+	if err != nil {
+		return 0
+	}
+
+	return i
+}
+
+func sysfsUint64(path string) uint64 {
+	i, err := strconv.ParseUint(sysfsString(path), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return i
+}
+
+func sysfsBool(path string) bool {
+	return sysfsString(path) == "1"
+}
+
+// Returns the base name of the target of a symlink, empty on error
+func linkBase(path string) string {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Base(target)
+}
+
+// Reads hexadecimal values such as 0x8086, zero on error
+func sysfsHex(path string) uint64 {
+	v, err := strconv.ParseUint(strings.TrimPrefix(sysfsString(path), "0x"), 16, 64)
+	if err != nil {
+		return 0
+	}
+
+	return v
+}
+
+// Returns the sorted names of the entries of dir, nil if it does not exist
+func dirNames(dir string) []string {
+	f, err := os.Open(dir)
+	if err != nil {
+		return []string(nil)
+	}
+	defer f.Close()
+
+	allNames := -1
+	names, err := f.Readdirnames(allNames)
+	if err != nil {
+		return []string(nil)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Returns the active value of a sysfs list such as "none [mq-deadline] bfq"
+func bracketedValue(s string) string {
+	start := strings.Index(s, "[")
+	end := strings.Index(s, "]")
+	if start == -1 || end < start {
+		return strings.TrimSpace(s)
+	}
+
+	return s[start+1 : end]
+}
+
+// Parses a kernel CPU list such as "0-3,8,10-11"
+func parseCPUList(list string) []int {
+	var cpus []int
+
+	for _, part := range strings.Split(strings.TrimSpace(list), ",") {
+		if part == "" {
+			continue
+		}
+
+		bounds := strings.Split(part, "-")
+
+		first, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			continue
+		}
+
+		last := first
+		if len(bounds) == 2 {
+			last, err = strconv.Atoi(bounds[1])
+			if err != nil {
+				continue
+			}
+		}
+
+		for cpu := first; cpu <= last; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+
+	return cpus
+}
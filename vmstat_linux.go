@@ -0,0 +1,156 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"strconv"
+	"strings"
+)
+
+var (
+	// Older kernels split some counters per memory zone, e.g.
+	// pgscan_kswapd_normal
+	memoryZones = []string{"dma", "dma32", "normal", "high", "movable", "device"}
+)
+
+// ----
+
+// Virtual memory counters from /proc/vmstat. All the counters are available
+// in Counters, the most commonly used ones are also exposed as fields and
+// are zero when the kernel does not report them.
+type VMStats struct {
+	Counters map[string]uint64
+
+	PgPgIn     uint64
+	PgPgOut    uint64
+	PswpIn     uint64
+	PswpOut    uint64
+	PgFault    uint64
+	PgMajFault uint64
+
+	PgScanKswapd  uint64
+	PgScanDirect  uint64
+	PgStealKswapd uint64
+	PgStealDirect uint64
+	AllocStall    uint64
+
+	OOMKill uint64
+
+	CompactStall   uint64
+	CompactFail    uint64
+	CompactSuccess uint64
+
+	THPFaultAlloc    uint64
+	THPFaultFallback uint64
+	THPCollapseAlloc uint64
+	THPSplitPage     uint64
+
+	NumaHit     uint64
+	NumaMiss    uint64
+	NumaForeign uint64
+	NumaLocal   uint64
+	NumaOther   uint64
+}
+
+// ----
+
+func VMStat() (VMStats, error) {
+	buff, err := getVMStat()
+	if err != nil {
+		return VMStats{}, err
+	}
+
+	return processVMStat(buff), nil
+}
+
+// Returns the increase of every counter since prev. Gauges such as the nr_*
+// values are subtracted as well, and are meaningless in the result.
+func (v VMStats) Delta(prev VMStats) VMStats {
+	counters := make(map[string]uint64, len(v.Counters))
+
+	for k, cur := range v.Counters {
+		counters[k] = counterDelta(prev.Counters[k], cur)
+	}
+
+	return newVMStats(counters)
+}
+
+// ----
+
+// Returns the value of a counter, summing its per zone variants if any
+func zonedCounter(counters map[string]uint64, name string) uint64 {
+	total := counters[name]
+
+	for _, zone := range memoryZones {
+		total += counters[name+"_"+zone]
+	}
+
+	return total
+}
+
+func newVMStats(counters map[string]uint64) VMStats {
+	return VMStats{
+		Counters: counters,
+
+		PgPgIn:     counters["pgpgin"],
+		PgPgOut:    counters["pgpgout"],
+		PswpIn:     counters["pswpin"],
+		PswpOut:    counters["pswpout"],
+		PgFault:    counters["pgfault"],
+		PgMajFault: counters["pgmajfault"],
+
+		PgScanKswapd:  zonedCounter(counters, "pgscan_kswapd"),
+		PgScanDirect:  zonedCounter(counters, "pgscan_direct"),
+		PgStealKswapd: zonedCounter(counters, "pgsteal_kswapd"),
+		PgStealDirect: zonedCounter(counters, "pgsteal_direct"),
+		AllocStall:    zonedCounter(counters, "allocstall"),
+
+		OOMKill: counters["oom_kill"],
+
+		CompactStall:   counters["compact_stall"],
+		CompactFail:    counters["compact_fail"],
+		CompactSuccess: counters["compact_success"],
+
+		THPFaultAlloc:    counters["thp_fault_alloc"],
+		THPFaultFallback: counters["thp_fault_fallback"],
+		THPCollapseAlloc: counters["thp_collapse_alloc"],
+		THPSplitPage:     counters["thp_split_page"],
+
+		NumaHit:     counters["numa_hit"],
+		NumaMiss:    counters["numa_miss"],
+		NumaForeign: counters["numa_foreign"],
+		NumaLocal:   counters["numa_local"],
+		NumaOther:   counters["numa_other"],
+	}
+}
+
+func processVMStat(buff string) VMStats {
+	return newVMStats(processKeyValues(buff))
+}
+
+// Parses "key value" lines such as the ones of /proc/vmstat
+func processKeyValues(buff string) map[string]uint64 {
+	values := make(map[string]uint64)
+
+	for _, line := range strings.Split(buff, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		values[fields[0]] = v
+	}
+
+	return values
+}
+
+// ----
+
+func getVMStat() (string, error) {
+	return readFile("/proc/vmstat")
+}
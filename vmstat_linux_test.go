@@ -0,0 +1,80 @@
+package libsysinfo
+
+import (
+	. "launchpad.net/gocheck"
+)
+
+type VMStatTestSuite struct{}
+
+var (
+	_ = Suite(&VMStatTestSuite{})
+)
+
+func (s *VMStatTestSuite) TestProcessVMStat(c *C) {
+	fixture := `nr_free_pages 820024
+pgpgin 1693228
+pgpgout 4187116
+pswpin 12
+pswpout 34
+pgfault 98812375
+pgmajfault 4180
+pgscan_kswapd 1500
+pgscan_direct 300
+pgscan_direct_throttle 7
+pgsteal_kswapd 1400
+pgsteal_direct 250
+oom_kill 2
+numa_hit 5238823
+numa_miss 0
+thp_fault_alloc 345
+compact_stall 4
+`
+	obtained := processVMStat(fixture)
+
+	c.Assert(len(obtained.Counters), Equals, 17)
+	c.Assert(obtained.Counters["nr_free_pages"], Equals, uint64(820024))
+	c.Assert(obtained.Counters["pgscan_direct_throttle"], Equals, uint64(7))
+
+	c.Assert(obtained.PgPgIn, Equals, uint64(1693228))
+	c.Assert(obtained.PgPgOut, Equals, uint64(4187116))
+	c.Assert(obtained.PswpIn, Equals, uint64(12))
+	c.Assert(obtained.PswpOut, Equals, uint64(34))
+	c.Assert(obtained.PgFault, Equals, uint64(98812375))
+	c.Assert(obtained.PgMajFault, Equals, uint64(4180))
+	c.Assert(obtained.PgScanKswapd, Equals, uint64(1500))
+	c.Assert(obtained.PgScanDirect, Equals, uint64(300))
+	c.Assert(obtained.PgStealKswapd, Equals, uint64(1400))
+	c.Assert(obtained.PgStealDirect, Equals, uint64(250))
+	c.Assert(obtained.OOMKill, Equals, uint64(2))
+	c.Assert(obtained.NumaHit, Equals, uint64(5238823))
+	c.Assert(obtained.THPFaultAlloc, Equals, uint64(345))
+	c.Assert(obtained.CompactStall, Equals, uint64(4))
+	c.Assert(obtained.AllocStall, Equals, uint64(0))
+}
+
+func (s *VMStatTestSuite) TestProcessVMStat_ZonedCounters(c *C) {
+	fixture := `pgscan_kswapd_dma 10
+pgscan_kswapd_dma32 20
+pgscan_kswapd_normal 30
+pgscan_kswapd_movable 40
+allocstall_normal 3
+allocstall_movable 1
+`
+	obtained := processVMStat(fixture)
+
+	c.Assert(obtained.PgScanKswapd, Equals, uint64(100))
+	c.Assert(obtained.AllocStall, Equals, uint64(4))
+}
+
+func (s *VMStatTestSuite) TestDelta(c *C) {
+	before := processVMStat("pgfault 100\npgmajfault 10\noom_kill 1\n")
+	after := processVMStat("pgfault 150\npgmajfault 10\noom_kill 2\nthp_fault_alloc 5\n")
+
+	obtained := after.Delta(before)
+
+	c.Assert(obtained.PgFault, Equals, uint64(50))
+	c.Assert(obtained.PgMajFault, Equals, uint64(0))
+	c.Assert(obtained.OOMKill, Equals, uint64(1))
+	c.Assert(obtained.THPFaultAlloc, Equals, uint64(5))
+	c.Assert(len(obtained.Counters), Equals, 4)
+}
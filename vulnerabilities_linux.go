@@ -0,0 +1,186 @@
+// +build linux
+
+package libsysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	sysVulnerabilitiesPath = "/sys/devices/system/cpu/vulnerabilities"
+)
+
+var (
+	ErrVulnerabilitiesNotAvailable = &LibSysInfoErr{"CPU vulnerabilities informations not available"}
+)
+
+// ----
+
+type VulnerabilityStatus int
+
+const (
+	// e.g. "Unknown: Dependent on hypervisor status"
+	VulnerabilityUnknown VulnerabilityStatus = iota
+	VulnerabilityNotAffected
+	VulnerabilityVulnerable
+	VulnerabilityMitigated
+)
+
+func (s VulnerabilityStatus) String() string {
+	switch s {
+	case VulnerabilityNotAffected:
+		return "not affected"
+	case VulnerabilityVulnerable:
+		return "vulnerable"
+	case VulnerabilityMitigated:
+		return "mitigated"
+	}
+
+	return "unknown"
+}
+
+// ----
+
+// A hardware vulnerability as reported by the kernel, e.g.
+//
+//	Name:       spectre_v2
+//	Status:     VulnerabilityMitigated
+//	Mitigation: Enhanced / Automatic IBRS
+//	Details:    [IBPB: conditional, BHI: Vulnerable]
+type CPUVulnerability struct {
+	Name   string
+	Status VulnerabilityStatus
+
+	// The mitigation in use, only set when Status is VulnerabilityMitigated
+	Mitigation string
+
+	// Additional states reported after the main one, e.g. "SMT vulnerable"
+	Details []string
+
+	// The line reported by the kernel
+	Raw string
+}
+
+// ----
+
+// Returns the vulnerabilities known to the kernel, sorted by name
+func CPUVulnerabilities() ([]CPUVulnerability, error) {
+	_, err := os.Stat(sysVulnerabilitiesPath)
+	if os.IsNotExist(err) {
+		// the directory appeared in 4.15
+		return []CPUVulnerability(nil), ErrVulnerabilitiesNotAvailable
+	}
+	if err != nil {
+		return []CPUVulnerability(nil), err
+	}
+
+	return readCPUVulnerabilities(sysVulnerabilitiesPath), nil
+}
+
+// Returns the microcode revision of every online CPU, e.g. 0xf0, indexed by
+// CPU id. The revision comes from sysfs when available, from /proc/cpuinfo
+// otherwise.
+func MicrocodeRevisions() (map[int]string, error) {
+	revisions := readMicrocodeRevisions(sysCPUPath)
+	if len(revisions) > 0 {
+		return revisions, nil
+	}
+
+	cpus, err := CpuInfos()
+	if err != nil {
+		return revisions, err
+	}
+
+	for _, cpu := range cpus {
+		id, err := strconv.Atoi(cpu.Processor)
+		if err != nil || cpu.Microcode == "" {
+			continue
+		}
+
+		revisions[id] = cpu.Microcode
+	}
+
+	return revisions, nil
+}
+
+// ----
+
+func readCPUVulnerabilities(dir string) []CPUVulnerability {
+	var vulnerabilities []CPUVulnerability
+
+	for _, name := range dirNames(dir) {
+		v := processVulnerability(sysfsString(filepath.Join(dir, name)))
+		v.Name = name
+
+		vulnerabilities = append(vulnerabilities, v)
+	}
+
+	return vulnerabilities
+}
+
+func processVulnerability(line string) CPUVulnerability {
+	v := CPUVulnerability{
+		Raw: line,
+	}
+
+	// details are separated by "; " on recent kernels and by ", " on older
+	// ones, e.g. "Mitigation: Full generic retpoline, IBPB: conditional"
+	sep := ";"
+	if !strings.Contains(line, sep) {
+		sep = ", "
+	}
+
+	parts := strings.Split(line, sep)
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	// itlb_multihit reports the KVM mitigation status with a prefix
+	state := strings.TrimPrefix(parts[0], "KVM: ")
+
+	switch {
+	case state == "Not affected":
+		v.Status = VulnerabilityNotAffected
+	case strings.HasPrefix(state, "Mitigation: "):
+		v.Status = VulnerabilityMitigated
+		v.Mitigation = strings.TrimPrefix(state, "Mitigation: ")
+	case state == "Processor vulnerable":
+		v.Status = VulnerabilityVulnerable
+	case strings.HasPrefix(state, "Vulnerable"):
+		v.Status = VulnerabilityVulnerable
+
+		// e.g. "Vulnerable: Clear CPU buffers attempted, no microcode"
+		detail := strings.TrimLeft(strings.TrimPrefix(state, "Vulnerable"), ":, ")
+		if detail != "" {
+			v.Details = append(v.Details, detail)
+		}
+	default:
+		v.Status = VulnerabilityUnknown
+	}
+
+	for _, p := range parts[1:] {
+		if p != "" {
+			v.Details = append(v.Details, p)
+		}
+	}
+
+	return v
+}
+
+func readMicrocodeRevisions(root string) map[int]string {
+	revisions := make(map[int]string)
+
+	for _, id := range onlineCPUs(root) {
+		path := filepath.Join(root, "cpu"+strconv.Itoa(id), "microcode", "version")
+
+		version := sysfsString(path)
+		if version != "" {
+			revisions[id] = version
+		}
+	}
+
+	return revisions
+}
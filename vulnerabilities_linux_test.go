@@ -0,0 +1,115 @@
+package libsysinfo
+
+import (
+	. "launchpad.net/gocheck"
+)
+
+type VulnerabilitiesTestSuite struct{}
+
+var (
+	_ = Suite(&VulnerabilitiesTestSuite{})
+)
+
+func (s *VulnerabilitiesTestSuite) TestReadCPUVulnerabilities(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"meltdown":      "Not affected\n",
+		"spectre_v2":    "Mitigation: Enhanced / Automatic IBRS; IBPB: conditional; BHI: Vulnerable\n",
+		"mds":           "Vulnerable: Clear CPU buffers attempted, no microcode; SMT vulnerable\n",
+		"itlb_multihit": "KVM: Mitigation: VMX disabled\n",
+		"srbds":         "Unknown: Dependent on hypervisor status\n",
+	})
+
+	obtained := readCPUVulnerabilities(root)
+
+	expected := []CPUVulnerability{
+		CPUVulnerability{
+			Name:       "itlb_multihit",
+			Status:     VulnerabilityMitigated,
+			Mitigation: "VMX disabled",
+			Raw:        "KVM: Mitigation: VMX disabled",
+		},
+		CPUVulnerability{
+			Name:    "mds",
+			Status:  VulnerabilityVulnerable,
+			Details: []string{"Clear CPU buffers attempted, no microcode", "SMT vulnerable"},
+			Raw:     "Vulnerable: Clear CPU buffers attempted, no microcode; SMT vulnerable",
+		},
+		CPUVulnerability{
+			Name:   "meltdown",
+			Status: VulnerabilityNotAffected,
+			Raw:    "Not affected",
+		},
+		CPUVulnerability{
+			Name:       "spectre_v2",
+			Status:     VulnerabilityMitigated,
+			Mitigation: "Enhanced / Automatic IBRS",
+			Details:    []string{"IBPB: conditional", "BHI: Vulnerable"},
+			Raw:        "Mitigation: Enhanced / Automatic IBRS; IBPB: conditional; BHI: Vulnerable",
+		},
+		CPUVulnerability{
+			Name:   "srbds",
+			Status: VulnerabilityUnknown,
+			Raw:    "Unknown: Dependent on hypervisor status",
+		},
+	}
+
+	c.Assert(obtained, DeepEquals, expected)
+}
+
+func (s *VulnerabilitiesTestSuite) TestProcessVulnerability_Vulnerable(c *C) {
+	obtained := processVulnerability("Vulnerable, IBPB: disabled, STIBP: disabled")
+
+	c.Assert(obtained.Status, Equals, VulnerabilityVulnerable)
+	c.Assert(obtained.Details, DeepEquals, []string{"IBPB: disabled", "STIBP: disabled"})
+
+	obtained = processVulnerability("Processor vulnerable")
+	c.Assert(obtained.Status, Equals, VulnerabilityVulnerable)
+	c.Assert(obtained.Details, IsNil)
+	c.Assert(obtained.Status.String(), Equals, "vulnerable")
+}
+
+func (s *VulnerabilitiesTestSuite) TestProcessVulnerability_CommaSeparated(c *C) {
+	obtained := processVulnerability("Mitigation: Full generic retpoline, IBPB: conditional, IBRS_FW, STIBP: disabled, RSB filling")
+
+	c.Assert(obtained, DeepEquals, CPUVulnerability{
+		Status:     VulnerabilityMitigated,
+		Mitigation: "Full generic retpoline",
+		Details:    []string{"IBPB: conditional", "IBRS_FW", "STIBP: disabled", "RSB filling"},
+		Raw:        "Mitigation: Full generic retpoline, IBPB: conditional, IBRS_FW, STIBP: disabled, RSB filling",
+	})
+
+	obtained = processVulnerability("Mitigation: __user pointer sanitization, usercopy/swapgs barriers")
+	c.Assert(obtained.Mitigation, Equals, "__user pointer sanitization")
+	c.Assert(obtained.Details, DeepEquals, []string{"usercopy/swapgs barriers"})
+
+	obtained = processVulnerability("Mitigation: PTI")
+	c.Assert(obtained.Mitigation, Equals, "PTI")
+	c.Assert(obtained.Details, IsNil)
+}
+
+func (s *VulnerabilitiesTestSuite) TestReadMicrocodeRevisions(c *C) {
+	root := c.MkDir()
+
+	writeFixtureFiles(c, root, map[string]string{
+		"online":                 "0-2\n",
+		"cpu0/microcode/version": "0xf0\n",
+		"cpu1/microcode/version": "0xf4\n",
+		"cpu2/microcode/uevent":  "",
+		"cpu3/microcode/version": "0xf0\n",
+	})
+
+	c.Assert(readMicrocodeRevisions(root), DeepEquals, map[int]string{0: "0xf0", 1: "0xf4"})
+}
+
+func (s *VulnerabilitiesTestSuite) TestProcessCpuInfos_Microcode(c *C) {
+	fixtures := `processor	: 0
+microcode	: 0xde
+
+`
+	obtained := processCpuInfos(fixtures)
+
+	c.Assert(len(obtained), Equals, 1)
+	c.Assert(obtained[0].Microcode, Equals, "0xde")
+}